@@ -0,0 +1,308 @@
+// Package banks is a registry of the institutions that take part in the Brazilian payment
+// system, read from the list of STR participants published by the Banco Central do Brasil
+// (ParticipantesSTR.csv).
+//
+// The bundled dataset is a subset of the official file: the rows of the main banks that issue
+// boletos, with the ISPB, Nome_Reduzido, Número_Código and Nome_Extenso columns only, so it
+// has no Compe status, access or start date. Run go generate to replace it with the official
+// file, or load that file with LoadFile and SetDefault.
+package banks
+
+//go:generate curl -fsSL -o data/participants.csv https://www.bcb.gov.br/content/estabilidadefinanceira/str1/ParticipantesSTR.csv
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+//go:embed data/participants.csv
+var dataset []byte
+
+// ErrInvalidDataset is returned when a participants file can't be read
+var ErrInvalidDataset = errors.New("invalid bank dataset")
+
+// Status tells whether an institution takes part in Compe, the clearing of boletos and
+// checks. It is empty when the dataset doesn't say.
+type Status string
+
+const (
+	Active   Status = "ACTIVE"
+	Inactive Status = "INACTIVE"
+)
+
+// Direct and indirect access to the STR
+const (
+	DirectAccess   = "Direto"
+	IndirectAccess = "Indireto"
+)
+
+// Bank is a participant of the STR. Code is the 3-digit compe code printed on boletos,
+// empty for participants without one, and ISPB the 8-digit identifier used by PIX and TED.
+// Since is the zero time when the dataset has no start date.
+type Bank struct {
+	Code      string
+	ISPB      string
+	ShortName string
+	Name      string
+	Status    Status
+	Access    string
+	Since     time.Time
+}
+
+// Registry is an immutable list of banks, sorted by compe code
+type Registry struct {
+	banks  []Bank
+	byCode map[string]int
+	byISPB map[string]int
+}
+
+// NewRegistry returns a registry of banks
+func NewRegistry(banks []Bank) *Registry {
+	r := &Registry{banks: append([]Bank(nil), banks...)}
+
+	sort.SliceStable(r.banks, func(i, j int) bool {
+		if r.banks[i].Code != r.banks[j].Code {
+			// participants without a code go last
+			return r.banks[j].Code == "" || r.banks[i].Code != "" && r.banks[i].Code < r.banks[j].Code
+		}
+
+		return r.banks[i].ISPB < r.banks[j].ISPB
+	})
+
+	r.byCode = map[string]int{}
+	r.byISPB = map[string]int{}
+
+	// the first bank listed with a code or ISPB wins
+	for i := len(r.banks) - 1; i >= 0; i-- {
+		if r.banks[i].Code != "" {
+			r.byCode[r.banks[i].Code] = i
+		}
+
+		if r.banks[i].ISPB != "" {
+			r.byISPB[r.banks[i].ISPB] = i
+		}
+	}
+
+	return r
+}
+
+// Banks returns the banks of the registry
+func (r *Registry) Banks() []Bank {
+	return append([]Bank(nil), r.banks...)
+}
+
+// Len returns the number of banks in the registry
+func (r *Registry) Len() int {
+	return len(r.banks)
+}
+
+var (
+	mu      sync.RWMutex
+	current = mustLoad(dataset)
+)
+
+// Default returns the registry used by the package-level functions, the bundled dataset
+// unless SetDefault replaced it
+func Default() *Registry {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return current
+}
+
+// SetDefault replaces the registry used by the package-level functions, for example with
+// one read from a newer participants file
+func SetDefault(r *Registry) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current = r
+}
+
+// Bundled returns the registry of the bundled dataset
+func Bundled() *Registry {
+	return mustLoad(dataset)
+}
+
+func mustLoad(data []byte) *Registry {
+	r, err := Load(bytes.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// LoadFile reads a participants file, as downloaded from the Banco Central
+func LoadFile(path string) (*Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// columns of the participants file
+const (
+	columnISPB      = "ISPB"
+	columnShortName = "Nome_Reduzido"
+	columnCode      = "Número_Código"
+	columnCompe     = "Participa_da_Compe"
+	columnAccess    = "Acesso_Principal"
+	columnName      = "Nome_Extenso"
+	columnSince     = "Início_da_Operação"
+)
+
+// Load reads banks in the format of the STR participants file: a CSV with a header row
+// naming the columns, in any order. Files in Latin-1, as older versions were published,
+// are accepted too.
+func Load(r io.Reader) (*Registry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if !utf8.Valid(data) {
+		data = latin1ToUTF8(data)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDataset, err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+
+	for _, name := range []string{columnISPB, columnCode, columnName} {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidDataset, name)
+		}
+	}
+
+	var banks []Bank
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDataset, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		value := func(column string) string {
+			i, ok := index[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+
+			return strings.TrimSpace(record[i])
+		}
+
+		bank, err := parseBank(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidDataset, line, err)
+		}
+
+		banks = append(banks, bank)
+	}
+
+	return NewRegistry(banks), nil
+}
+
+func parseBank(value func(column string) string) (Bank, error) {
+	bank := Bank{
+		ISPB:      value(columnISPB),
+		Code:      value(columnCode),
+		ShortName: value(columnShortName),
+		Name:      value(columnName),
+		Access:    value(columnAccess),
+	}
+
+	if bank.ISPB != "" {
+		if len(bank.ISPB) > 8 || !isDigits(bank.ISPB) {
+			return bank, fmt.Errorf("invalid ISPB %q", bank.ISPB)
+		}
+
+		bank.ISPB = strings.Repeat("0", 8-len(bank.ISPB)) + bank.ISPB
+	}
+
+	switch {
+	case strings.EqualFold(bank.Code, "n/a"):
+		bank.Code = ""
+	case bank.Code != "":
+		if len(bank.Code) > 3 || !isDigits(bank.Code) {
+			return bank, fmt.Errorf("invalid code %q", bank.Code)
+		}
+
+		bank.Code = strings.Repeat("0", 3-len(bank.Code)) + bank.Code
+	}
+
+	if bank.Code == "" && bank.ISPB == "" {
+		return bank, errors.New("missing code and ISPB")
+	}
+
+	if bank.Name == "" {
+		bank.Name = bank.ShortName
+	}
+
+	switch compe := value(columnCompe); {
+	case strings.EqualFold(compe, "Sim") || strings.EqualFold(compe, "S"):
+		bank.Status = Active
+	case compe != "":
+		bank.Status = Inactive
+	}
+
+	if since := value(columnSince); since != "" {
+		date, err := time.Parse("02/01/2006", since)
+		if err != nil {
+			return bank, fmt.Errorf("invalid start date %q", since)
+		}
+
+		bank.Since = date
+	}
+
+	return bank, nil
+}
+
+func latin1ToUTF8(data []byte) []byte {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+
+	return []byte(string(runes))
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
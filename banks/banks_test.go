@@ -0,0 +1,206 @@
+package banks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const participants = `ISPB,Nome_Reduzido,Número_Código,Participa_da_Compe,Acesso_Principal,Nome_Extenso,Início_da_Operação
+00000000,BCO DO BRASIL S.A.,001,Sim,Direto,Banco do Brasil S.A.,22/04/2002
+00038166,BCB,n/a,Não,Direto,Banco Central do Brasil,22/04/2002
+37880206,CORA SCD S.A.,403,Sim,Indireto,Cora Sociedade de Crédito Direto S.A.,09/11/2020
+416968,BANCO INTER,77,Sim,Direto,Banco Inter S.A.,
+`
+
+func TestValues_Load(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Bank
+		err   error
+	}{
+		{participants,
+			[]Bank{
+				{Code: "001", ISPB: "00000000", ShortName: "BCO DO BRASIL S.A.", Name: "Banco do Brasil S.A.", Status: Active, Access: DirectAccess, Since: time.Date(2002, 4, 22, 0, 0, 0, 0, time.UTC)},
+				{Code: "077", ISPB: "00416968", ShortName: "BANCO INTER", Name: "Banco Inter S.A.", Status: Active, Access: DirectAccess},
+				{Code: "403", ISPB: "37880206", ShortName: "CORA SCD S.A.", Name: "Cora Sociedade de Crédito Direto S.A.", Status: Active, Access: IndirectAccess, Since: time.Date(2020, 11, 9, 0, 0, 0, 0, time.UTC)},
+				{ISPB: "00038166", ShortName: "BCB", Name: "Banco Central do Brasil", Status: Inactive, Access: DirectAccess, Since: time.Date(2002, 4, 22, 0, 0, 0, 0, time.UTC)},
+			},
+			nil,
+		},
+		{"Nome_Extenso,Número_Código,ISPB\nBanco Inter S.A.,077,00416968\n",
+			[]Bank{{Code: "077", ISPB: "00416968", Name: "Banco Inter S.A."}},
+			nil,
+		},
+		{"ISPB,Nome_Reduzido\n00000000,BCO DO BRASIL S.A.\n", nil, ErrInvalidDataset},
+		{"ISPB,Número_Código,Nome_Extenso\n0000000X,001,Banco do Brasil S.A.\n", nil, ErrInvalidDataset},
+		{"ISPB,Número_Código,Nome_Extenso\n00000000,1234,Banco do Brasil S.A.\n", nil, ErrInvalidDataset},
+		{"ISPB,Número_Código,Nome_Extenso\n,,Banco do Brasil S.A.\n", nil, ErrInvalidDataset},
+		{"ISPB,Número_Código,Nome_Extenso,Início_da_Operação\n00000000,001,Banco do Brasil S.A.,2002-04-22\n", nil, ErrInvalidDataset},
+		{"", nil, ErrInvalidDataset},
+	}
+
+	for _, tt := range tests {
+		got, err := Load(strings.NewReader(tt.input))
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Load(%q) returned error %v, want %v", tt.input, err, tt.err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if diff := cmp.Diff(tt.want, got.Banks()); diff != "" {
+			t.Errorf("Load(%q) mismatch (-want +got):\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestValues_LoadLatin1(t *testing.T) {
+	latin1 := []byte("ISPB,N\xfamero_C\xf3digo,Nome_Extenso\n00000000,001,Banco do Brasil S.A.\n02038232,756,Banco Cooperativo Sicoob S.A. - Banco Sicoob\n")
+
+	path := filepath.Join(t.TempDir(), "ParticipantesSTR.csv")
+	if err := os.WriteFile(path, latin1, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile returned %v", err)
+	}
+
+	if registry.Len() != 2 || registry.Banks()[1].Code != "756" {
+		t.Errorf("LoadFile returned %+v", registry.Banks())
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Errorf("LoadFile with a missing file returned no error")
+	}
+}
+
+func TestValues_Bundled(t *testing.T) {
+	registry := Bundled()
+
+	codes := map[string]Bank{}
+	for _, bank := range registry.Banks() {
+		if _, ok := codes[bank.Code]; ok {
+			t.Errorf("bundled dataset lists code %s twice", bank.Code)
+		}
+
+		codes[bank.Code] = bank
+	}
+
+	want := Bank{Code: "403", ISPB: "37880206", ShortName: "CORA SCD S.A.", Name: "Cora Sociedade de Crédito Direto S.A."}
+	if diff := cmp.Diff(want, codes["403"]); diff != "" {
+		t.Errorf("bundled dataset mismatch (-want +got):\n%s", diff)
+	}
+
+	if Default().Len() != registry.Len() {
+		t.Errorf("Default() has %d banks, want %d", Default().Len(), registry.Len())
+	}
+
+	custom, _ := Load(strings.NewReader(participants))
+	SetDefault(custom)
+	defer SetDefault(registry)
+
+	if Default() != custom {
+		t.Errorf("SetDefault did not replace the default registry")
+	}
+}
+
+func TestValues_Lookup(t *testing.T) {
+	registry, _ := Load(strings.NewReader(participants))
+
+	tests := []struct {
+		lookup func(string) (Bank, error)
+		input  string
+		want   string
+		err    error
+	}{
+		{registry.ByCode, "001", "Banco do Brasil S.A.", nil},
+		{registry.ByCode, "077", "Banco Inter S.A.", nil},
+		{registry.ByCode, "77", "", ErrUnknownBank},
+		{registry.ByCode, "", "", ErrUnknownBank},
+		{registry.ByISPB, "37880206", "Cora Sociedade de Crédito Direto S.A.", nil},
+		{registry.ByISPB, "00038166", "Banco Central do Brasil", nil},
+		{registry.ByISPB, "99999999", "", ErrUnknownBank},
+		{ByCode, "341", "Itaú Unibanco S.A.", nil},
+		{ByISPB, "18236120", "Nu Pagamentos S.A. - Instituição de Pagamento", nil},
+		{ByCode, "999", "", ErrUnknownBank},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.lookup(tt.input)
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("lookup(%v) returned error %v, want %v", tt.input, err, tt.err)
+			continue
+		}
+
+		if got.Name != tt.want {
+			t.Errorf("lookup(%v) = %v, want %v", tt.input, got.Name, tt.want)
+		}
+	}
+}
+
+func TestValues_LookupBundled(t *testing.T) {
+	registry := Bundled()
+
+	for _, bank := range registry.Banks() {
+		if bank.Code == "" || bank.ISPB == "" {
+			t.Errorf("bundled bank %q has code %q and ISPB %q", bank.Name, bank.Code, bank.ISPB)
+			continue
+		}
+
+		if got, err := registry.ByCode(bank.Code); err != nil || got != bank {
+			t.Errorf("ByCode(%s) = %+v, %v, want %+v", bank.Code, got, err, bank)
+		}
+
+		if got, err := registry.ByISPB(bank.ISPB); err != nil || got != bank {
+			t.Errorf("ByISPB(%s) = %+v, %v, want %+v", bank.ISPB, got, err, bank)
+		}
+	}
+}
+
+func TestValues_Search(t *testing.T) {
+	registry, _ := Load(strings.NewReader(participants))
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"banco", []string{"001", "077", ""}},
+		{"BRASIL", []string{"001", ""}},
+		{"credito direto", []string{"403"}},
+		{"Crédito  DIRETO", []string{"403"}},
+		{"cora scd s.a.", []string{"403"}},
+		{"inter sa", []string{"077"}},
+		{"do brasil", []string{"001", ""}},
+		{"0", []string{"001", "077", ""}},
+		{"40", []string{"403"}},
+		{"itau", nil},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, bank := range registry.Search(tt.query) {
+			got = append(got, bank.Code)
+		}
+
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("Search(%q) mismatch (-want +got):\n%s", tt.query, diff)
+		}
+	}
+
+	if got := Search("itau unibanco"); len(got) == 0 || got[0].Code != "341" {
+		t.Errorf("Search(itau unibanco) = %+v", got)
+	}
+}
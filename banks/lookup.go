@@ -0,0 +1,112 @@
+package banks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrUnknownBank is returned when no bank in the registry matches a code or ISPB
+var ErrUnknownBank = errors.New("unknown bank")
+
+// ByCode returns the bank with the 3-digit compe code
+func (r *Registry) ByCode(code string) (Bank, error) {
+	if i, ok := r.byCode[code]; ok {
+		return r.banks[i], nil
+	}
+
+	return Bank{}, fmt.Errorf("%w: code %q", ErrUnknownBank, code)
+}
+
+// ByISPB returns the bank with the 8-digit ISPB
+func (r *Registry) ByISPB(ispb string) (Bank, error) {
+	if i, ok := r.byISPB[ispb]; ok {
+		return r.banks[i], nil
+	}
+
+	return Bank{}, fmt.Errorf("%w: ISPB %q", ErrUnknownBank, ispb)
+}
+
+// Search returns the banks whose short or full name contains every word of query, ignoring
+// case and accents. Banks whose name starts with the query come first; a query of digits
+// also matches the start of codes and ISPBs.
+func (r *Registry) Search(query string) []Bank {
+	prefix := fold(query)
+	if prefix == "" {
+		return nil
+	}
+
+	words := strings.Fields(prefix)
+	digits := len(words) == 1 && isDigits(prefix)
+
+	var first, rest []Bank
+
+	for _, bank := range r.banks {
+		shortName, name := fold(bank.ShortName), fold(bank.Name)
+
+		switch {
+		case strings.HasPrefix(shortName, prefix) || strings.HasPrefix(name, prefix):
+			first = append(first, bank)
+		case digits && (strings.HasPrefix(bank.Code, prefix) || strings.HasPrefix(bank.ISPB, prefix)):
+			first = append(first, bank)
+		case containsAll(shortName+" "+name, words):
+			rest = append(rest, bank)
+		}
+	}
+
+	return append(first, rest...)
+}
+
+// ByCode returns the bank with the compe code in the default registry
+func ByCode(code string) (Bank, error) {
+	return Default().ByCode(code)
+}
+
+// ByISPB returns the bank with the ISPB in the default registry
+func ByISPB(ispb string) (Bank, error) {
+	return Default().ByISPB(ispb)
+}
+
+// Search searches the names of the default registry
+func Search(query string) []Bank {
+	return Default().Search(query)
+}
+
+func containsAll(s string, words []string) bool {
+	for _, word := range words {
+		if !strings.Contains(s, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// fold lowers the case of s, removes its accents and turns punctuation into spaces, so
+// "Itaú Unibanco S.A." matches "itau unibanco sa"
+func fold(s string) string {
+	s = accents.Replace(strings.ToLower(s))
+
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '.':
+			return -1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return r
+		default:
+			return ' '
+		}
+	}, s)
+
+	return strings.Join(strings.Fields(s), " ")
+}
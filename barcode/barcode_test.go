@@ -0,0 +1,262 @@
+package barcode
+
+import (
+	"bytes"
+	"errors"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+const testCode = "34191990600000005001092664672997197273480000"
+
+func TestValues_Encode(t *testing.T) {
+	tests := []struct {
+		input  string
+		prefix string
+		err    error
+	}{
+		{testCode, "nnnn" + "wnwnnwnnnw" + "wnnwnnnwwn", nil},
+		{"82670000003645607980000100023510382202411671", "nnnn" + "wnnwnnwnnw", nil},
+		{"34191990600000006001092664672997197273480000", "", ErrInvalidCode},
+		{"3419199060000000500109266467299719727348000", "", ErrInvalidCode},
+		{"34191.09263 64672.997190 72734.800005 1 99060000000500", "", ErrInvalidCode},
+	}
+
+	for _, tt := range tests {
+		got, err := Encode(tt.input)
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Encode(%v) returned error %v, want %v", tt.input, err, tt.err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if len(got) != 4+Length*5+3 || !strings.HasPrefix(got, tt.prefix) || !strings.HasSuffix(got, "wnn") {
+			t.Errorf("Encode(%v) = %v, want prefix %v", tt.input, got, tt.prefix)
+		}
+	}
+}
+
+func TestValues_Image(t *testing.T) {
+	tests := []struct {
+		options Options
+		width   int
+		height  int
+		black   []int
+		white   []int
+		err     error
+	}{
+		{Options{}, 850, 100, []int{20, 21, 24, 28, 33}, []int{0, 19, 22, 26, 34, 849}, nil},
+		{Options{ModuleWidth: 1, WideRatio: 2.5, Height: 40, QuietZone: 12}, 12 + 4 + 44*(3+2*3) + 2 + 3 + 12, 40, []int{12, 14, 16, 18}, []int{11, 13, 15, 19}, nil},
+		{Options{ModuleWidth: 1, WideRatio: 2.25, Height: 40, QuietZone: 12}, 12 + 4 + 44*(3+2*3) + 2 + 3 + 12, 40, []int{12, 14, 16, 18}, []int{11, 13, 15, 19}, nil},
+		{Options{ModuleWidth: 2, WideRatio: 2.25, Height: 40, QuietZone: 12}, 24 + 8 + 44*(6+2*5) + 4 + 5 + 24, 40, []int{24, 28, 32, 36}, []int{23, 26, 30, 38}, nil},
+		{Options{WideRatio: 2}, 0, 0, nil, nil, ErrInvalidOptions},
+		{Options{Height: -1}, 0, 0, nil, nil, ErrInvalidOptions},
+	}
+
+	for _, tt := range tests {
+		img, err := Image(testCode, tt.options)
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Image(%+v) returned error %v, want %v", tt.options, err, tt.err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if bounds := img.Bounds(); bounds.Dx() != tt.width || bounds.Dy() != tt.height {
+			t.Errorf("Image(%+v) is %dx%d, want %dx%d", tt.options, bounds.Dx(), bounds.Dy(), tt.width, tt.height)
+		}
+
+		for _, x := range tt.black {
+			if img.At(x, tt.height/2) != (color.Gray{}) {
+				t.Errorf("Image(%+v) pixel %d is white, want black", tt.options, x)
+			}
+		}
+
+		for _, x := range tt.white {
+			if img.At(x, tt.height/2) != (color.Gray{Y: 0xff}) {
+				t.Errorf("Image(%+v) pixel %d is black, want white", tt.options, x)
+			}
+		}
+	}
+}
+
+func TestValues_WritePNG(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := WritePNG(&buf, testCode, Options{}); err != nil {
+		t.Fatalf("WritePNG returned %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode returned %v", err)
+	}
+
+	if bounds := img.Bounds(); bounds.Dx() != 850 || bounds.Dy() != 100 {
+		t.Errorf("WritePNG wrote a %dx%d image, want 850x100", bounds.Dx(), bounds.Dy())
+	}
+}
+
+func TestValues_WriteSVG(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := WriteSVG(&buf, testCode, Options{}); err != nil {
+		t.Fatalf("WriteSVG returned %v", err)
+	}
+
+	svg := buf.String()
+
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="850" height="100" viewBox="0 0 850 100"`) {
+		t.Errorf("WriteSVG wrote an unexpected header: %.100s", svg)
+	}
+
+	// 2 start bars, 5 bars per digit pair and 2 stop bars, plus the background
+	if got := strings.Count(svg, "<rect"); got != 1+2+Length/2*5+2 {
+		t.Errorf("WriteSVG wrote %d rects, want %d", got, 1+2+Length/2*5+2)
+	}
+
+	if !strings.Contains(svg, `<rect x="28" width="6" height="100"/>`) {
+		t.Errorf("WriteSVG did not draw the first wide bar")
+	}
+}
+
+// drawPattern draws a pattern with no check digit validation, on a light gray canvas with a
+// margin around the bars
+func drawPattern(pattern string, module int, wide int, margin int) *image.Gray {
+	width := 2 * margin
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == Wide {
+			width += wide
+		} else {
+			width += module
+		}
+	}
+
+	img := image.NewGray(image.Rect(0, 0, width, 60+2*margin))
+	for i := range img.Pix {
+		img.Pix[i] = 0xe0
+	}
+
+	x := margin
+	for i := 0; i < len(pattern); i++ {
+		w := module
+		if pattern[i] == Wide {
+			w = wide
+		}
+
+		if i%2 == 0 {
+			for y := margin; y < margin+60; y++ {
+				for dx := 0; dx < w; dx++ {
+					img.SetGray(x+dx, y, color.Gray{Y: 0x30})
+				}
+			}
+		}
+
+		x += w
+	}
+
+	return img
+}
+
+func rotate180(src image.Image) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewGray(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dst.Set(bounds.Max.X-1-x+bounds.Min.X, bounds.Max.Y-1-y+bounds.Min.Y, src.At(x, y))
+		}
+	}
+
+	return dst
+}
+
+func TestValues_Decode(t *testing.T) {
+	rendered, _ := Image(testCode, Options{})
+	thin, _ := Image("82670000003645607980000100023510382202411671", Options{ModuleWidth: 1, WideRatio: 2.25, Height: 10})
+
+	// marks in the margin must not be taken for bars of the code
+	noisy := drawPattern(encode(testCode), 3, 8, 40)
+	for y := 0; y < noisy.Bounds().Dy(); y++ {
+		noisy.SetGray(2, y, color.Gray{})
+		noisy.SetGray(6, y, color.Gray{})
+		noisy.SetGray(7, y, color.Gray{})
+	}
+
+	badCheckDigit := "34191990600000006001092664672997197273480000"
+
+	tests := []struct {
+		name  string
+		input image.Image
+		want  string
+		err   error
+	}{
+		{"rendered", rendered, testCode, nil},
+		{"thin", thin, "82670000003645607980000100023510382202411671", nil},
+		{"rotated", rotate180(rendered), testCode, nil},
+		{"scanned", drawPattern(encode(testCode), 3, 8, 40), testCode, nil},
+		{"noisy", noisy, testCode, nil},
+		{"bad check digit", drawPattern(encode(badCheckDigit), 2, 5, 20), "", ErrInvalidCode},
+		{"blank", image.NewGray(image.Rect(0, 0, 100, 100)), "", ErrNotFound},
+		{"empty", image.NewGray(image.Rectangle{}), "", ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		got, err := Decode(tt.input)
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Decode(%s) returned error %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("Decode(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValues_Scan(t *testing.T) {
+	img, _ := Image(testCode, Options{})
+
+	boleto, err := Scan(img)
+	if err != nil {
+		t.Fatalf("Scan returned %v", err)
+	}
+
+	if boleto.IssuerBankCode != "341" || boleto.Amount != 500 {
+		t.Errorf("Scan returned bank %v and amount %v, want 341 and 500", boleto.IssuerBankCode, boleto.Amount)
+	}
+
+	if _, err := ScanArrecadacao(img); !errors.Is(err, parser.ErrNotArrecadacao) {
+		t.Errorf("ScanArrecadacao(bank boleto) returned %v, want ErrNotArrecadacao", err)
+	}
+}
+
+func TestValues_ScanArrecadacao(t *testing.T) {
+	img, _ := Image("82670000003645607980000100023510382202411671", Options{})
+
+	arrecadacao, err := ScanArrecadacao(img)
+	if err != nil {
+		t.Fatalf("ScanArrecadacao returned %v", err)
+	}
+
+	if arrecadacao.Segment != utils.Sanitation || arrecadacao.Amount != 36456 {
+		t.Errorf("ScanArrecadacao returned segment %v and amount %v, want sanitation and 36456", arrecadacao.Segment, arrecadacao.Amount)
+	}
+
+	if _, err := Scan(img); !errors.Is(err, parser.ErrArrecadacao) {
+		t.Errorf("Scan(arrecadação) returned %v, want ErrArrecadacao", err)
+	}
+}
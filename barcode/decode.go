@@ -0,0 +1,250 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+	"github.com/fonini/go-boleto-utils/validator"
+	"image"
+	"image/color"
+	"sort"
+)
+
+var ErrNotFound = errors.New("barcode not found")
+
+const (
+	// scanLines is the number of rows tried, spread around the middle of the image
+	scanLines = 21
+	// minContrast is the minimum difference between the darkest and lightest pixel of a row
+	minContrast = 48
+	// patternRuns is the number of bars and spaces of a 44-digit barcode, from start to stop code
+	patternRuns = len(startPattern) + Length*5 + len(stopPattern)
+)
+
+// Decode finds an ITF-25 boleto barcode with vertical bars in img and returns its 44 digits.
+// Rows are scanned from the middle of the image outwards, in both directions, so an image
+// rotated by 180 degrees is read as well. The digits must pass the check digit validation.
+func Decode(img image.Image) (string, error) {
+	bounds := img.Bounds()
+	height := bounds.Dy()
+
+	if bounds.Empty() {
+		return "", ErrNotFound
+	}
+
+	step := height / scanLines
+	if step == 0 {
+		step = 1
+	}
+
+	var checkErr error
+
+	for i := 0; i < scanLines; i++ {
+		offset := (i + 1) / 2 * step
+		if i%2 == 1 {
+			offset = -offset
+		}
+
+		y := bounds.Min.Y + height/2 + offset
+		if y < bounds.Min.Y || y >= bounds.Max.Y {
+			continue
+		}
+
+		runs := scanRuns(img, y)
+		if runs == nil {
+			continue
+		}
+
+		for _, r := range [][]int{runs, reverse(runs)} {
+			for _, code := range decodeRuns(r) {
+				err := validator.Check(code)
+				if err == nil {
+					return code, nil
+				}
+
+				checkErr = err
+			}
+		}
+	}
+
+	if checkErr != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCode, checkErr)
+	}
+
+	return "", ErrNotFound
+}
+
+// Scan decodes the barcode of a bank boleto in img and parses it. Arrecadação barcodes
+// return a *parser.ParseError wrapping parser.ErrArrecadacao; read them with ScanArrecadacao.
+func Scan(img image.Image) (*utils.Boleto, error) {
+	code, err := Decode(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.Parse(code)
+}
+
+// ScanArrecadacao decodes the barcode of an arrecadação code in img, such as a utility bill or
+// a tax slip, and parses it. Bank boleto barcodes return a *parser.ParseError wrapping
+// parser.ErrNotArrecadacao; read them with Scan.
+func ScanArrecadacao(img image.Image) (*utils.Arrecadacao, error) {
+	code, err := Decode(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.ParseArrecadacao(code)
+}
+
+// scanRuns binarizes row y of img and returns the widths of its dark and light runs, starting
+// with a dark run. It returns nil when the row has too little contrast to hold a barcode.
+func scanRuns(img image.Image, y int) []int {
+	bounds := img.Bounds()
+	row := make([]uint8, bounds.Dx())
+
+	lightest, darkest := uint8(0), uint8(0xff)
+
+	for x := range row {
+		row[x] = color.GrayModel.Convert(img.At(bounds.Min.X+x, y)).(color.Gray).Y
+
+		lightest = max(lightest, row[x])
+		darkest = min(darkest, row[x])
+	}
+
+	if int(lightest)-int(darkest) < minContrast {
+		return nil
+	}
+
+	threshold := (int(lightest) + int(darkest)) / 2
+
+	var runs []int
+
+	dark := true
+	width := 0
+
+	for _, luminance := range row {
+		if (int(luminance) < threshold) == dark {
+			width++
+			continue
+		}
+
+		// a row starting with a light pixel gets an empty leading dark run
+		runs = append(runs, width)
+		dark = !dark
+		width = 1
+	}
+
+	runs = append(runs, width)
+
+	return runs
+}
+
+// decodeRuns returns the digits of every start code in runs followed by 44 decodable digits and a stop code.
+// Dark runs sit at even indexes.
+func decodeRuns(runs []int) []string {
+	var codes []string
+
+	for i := 2; i+patternRuns <= len(runs); i += 2 {
+		narrow := float64(runs[i]+runs[i+1]+runs[i+2]+runs[i+3]) / 4
+
+		if !isNarrow(runs[i:i+4], narrow) || float64(runs[i-1]) < 3*narrow {
+			continue
+		}
+
+		code, ok := decodeDigits(runs[i+len(startPattern):i+patternRuns-len(stopPattern)], narrow)
+		if !ok {
+			continue
+		}
+
+		stop := runs[i+patternRuns-len(stopPattern) : i+patternRuns]
+		if float64(stop[0]) < 1.75*narrow || !isNarrow(stop[1:], narrow) {
+			continue
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes
+}
+
+// decodeDigits decodes the interleaved digit pairs of runs, ten runs per pair
+func decodeDigits(runs []int, narrow float64) (string, bool) {
+	digits := make([]byte, 0, Length)
+
+	for i := 0; i < len(runs); i += 10 {
+		var bars, spaces [5]int
+
+		for j := 0; j < 5; j++ {
+			bars[j] = runs[i+2*j]
+			spaces[j] = runs[i+2*j+1]
+		}
+
+		first, ok := decodeDigit(bars, narrow)
+		if !ok {
+			return "", false
+		}
+
+		second, ok := decodeDigit(spaces, narrow)
+		if !ok {
+			return "", false
+		}
+
+		digits = append(digits, first, second)
+	}
+
+	return string(digits), true
+}
+
+// decodeDigit classifies the two widest of five elements as wide, as every digit has exactly
+// two wide elements, and looks the pattern up
+func decodeDigit(widths [5]int, narrow float64) (byte, bool) {
+	order := []int{0, 1, 2, 3, 4}
+	sort.SliceStable(order, func(a, b int) bool {
+		return widths[order[a]] > widths[order[b]]
+	})
+
+	// the narrowest wide element must stand out from the widest narrow one
+	if float64(widths[order[1]]) < 1.5*float64(widths[order[2]]) || float64(widths[order[1]]) < 1.5*narrow {
+		return 0, false
+	}
+
+	pattern := []byte("nnnnn")
+	pattern[order[0]] = Wide
+	pattern[order[1]] = Wide
+
+	for digit, p := range digitPatterns {
+		if p == string(pattern) {
+			return byte('0' + digit), true
+		}
+	}
+
+	return 0, false
+}
+
+// isNarrow reports whether every width is close to the narrow width
+func isNarrow(widths []int, narrow float64) bool {
+	for _, width := range widths {
+		if float64(width) < 0.5*narrow || float64(width) > 1.5*narrow {
+			return false
+		}
+	}
+
+	return true
+}
+
+func reverse(runs []int) []int {
+	reversed := make([]int, 0, len(runs)+1)
+
+	// keep dark runs at even indexes
+	if len(runs)%2 == 0 {
+		reversed = append(reversed, 0)
+	}
+
+	for i := len(runs) - 1; i >= 0; i-- {
+		reversed = append(reversed, runs[i])
+	}
+
+	return reversed
+}
@@ -0,0 +1,79 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/utils"
+	"github.com/fonini/go-boleto-utils/validator"
+	"strings"
+)
+
+// Length is the number of digits of a boleto barcode
+const Length = 44
+
+// Elements of an encoded barcode. A pattern alternates bars and spaces, starting with a bar.
+const (
+	Narrow = 'n'
+	Wide   = 'w'
+)
+
+const (
+	startPattern = "nnnn"
+	stopPattern  = "wnn"
+)
+
+var (
+	ErrInvalidCode    = errors.New("invalid barcode")
+	ErrInvalidOptions = errors.New("invalid barcode options")
+)
+
+// digitPatterns holds the narrow and wide elements of each digit in Interleaved 2 of 5
+var digitPatterns = [10]string{
+	"nnwwn",
+	"wnnnw",
+	"nwnnw",
+	"wwnnn",
+	"nnwnw",
+	"wnwnn",
+	"nwwnn",
+	"nnnww",
+	"wnnwn",
+	"nwnwn",
+}
+
+// Encode returns the Interleaved 2 of 5 pattern of a 44-digit boleto barcode: the start
+// code, one element per bar and space of each digit pair and the stop code. Bars encode the
+// first digit of a pair and spaces the second. Codes with an invalid check digit are rejected,
+// so a misprinted boleto cannot be produced.
+func Encode(code string) (string, error) {
+	if len(code) != Length || utils.OnlyNumbers(code) != code {
+		return "", ErrInvalidCode
+	}
+
+	if err := validator.Check(code); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCode, err)
+	}
+
+	return encode(code), nil
+}
+
+// encode interleaves an even number of digits
+func encode(digits string) string {
+	var pattern strings.Builder
+
+	pattern.WriteString(startPattern)
+
+	for i := 0; i < len(digits); i += 2 {
+		bars := digitPatterns[digits[i]-'0']
+		spaces := digitPatterns[digits[i+1]-'0']
+
+		for j := 0; j < 5; j++ {
+			pattern.WriteByte(bars[j])
+			pattern.WriteByte(spaces[j])
+		}
+	}
+
+	pattern.WriteString(stopPattern)
+
+	return pattern.String()
+}
@@ -0,0 +1,172 @@
+package barcode
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Defaults of Options. FEBRABAN asks for a narrow to wide ratio between 1:2.25 and 1:3 and a
+// quiet zone of at least ten narrow modules on each side of the bars.
+const (
+	DefaultModuleWidth = 2
+	DefaultWideRatio   = 3
+	DefaultHeight      = 100
+	DefaultQuietZone   = 10
+)
+
+// Options configures how a barcode is rendered. Zero values are replaced by the defaults.
+type Options struct {
+	// ModuleWidth is the width in pixels of a narrow bar or space
+	ModuleWidth int
+	// WideRatio is the width of a wide element relative to a narrow one, from 2.25 to 3. The
+	// width in pixels is rounded, and rounded up when that keeps the ratio within range.
+	WideRatio float64
+	// Height is the height of the bars in pixels
+	Height int
+	// QuietZone is the blank margin left and right of the bars, in narrow modules
+	QuietZone int
+}
+
+func (o Options) withDefaults() (Options, error) {
+	if o.ModuleWidth == 0 {
+		o.ModuleWidth = DefaultModuleWidth
+	}
+
+	if o.WideRatio == 0 {
+		o.WideRatio = DefaultWideRatio
+	}
+
+	if o.Height == 0 {
+		o.Height = DefaultHeight
+	}
+
+	if o.QuietZone == 0 {
+		o.QuietZone = DefaultQuietZone
+	}
+
+	if o.ModuleWidth < 0 || o.Height < 0 || o.QuietZone < 0 || o.WideRatio < 2.25 || o.WideRatio > 3 {
+		return o, ErrInvalidOptions
+	}
+
+	return o, nil
+}
+
+// layout holds the width in pixels of each element of a pattern and of the quiet zone
+type layout struct {
+	widths    []int
+	quietZone int
+	width     int
+	height    int
+}
+
+func newLayout(code string, options Options) (*layout, error) {
+	options, err := options.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := Encode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	// a wide element rounded down below 2.25 narrow modules, such as 2 pixels for 1, is
+	// rejected by readers, so it is rounded up instead
+	wide := int(math.Round(float64(options.ModuleWidth) * options.WideRatio))
+	if float64(wide) < 2.25*float64(options.ModuleWidth) {
+		wide = int(math.Ceil(float64(options.ModuleWidth) * options.WideRatio))
+	}
+
+	l := &layout{
+		widths:    make([]int, len(pattern)),
+		quietZone: options.QuietZone * options.ModuleWidth,
+		height:    options.Height,
+	}
+
+	l.width = 2 * l.quietZone
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == Wide {
+			l.widths[i] = wide
+		} else {
+			l.widths[i] = options.ModuleWidth
+		}
+
+		l.width += l.widths[i]
+	}
+
+	return l, nil
+}
+
+// Image renders a 44-digit boleto barcode as black bars on a white background
+func Image(code string, options Options) (image.Image, error) {
+	l, err := newLayout(code, options)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewGray(image.Rect(0, 0, l.width, l.height))
+
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	x := l.quietZone
+
+	for i, width := range l.widths {
+		if i%2 == 0 {
+			for y := 0; y < l.height; y++ {
+				for dx := 0; dx < width; dx++ {
+					img.SetGray(x+dx, y, color.Gray{})
+				}
+			}
+		}
+
+		x += width
+	}
+
+	return img, nil
+}
+
+// WritePNG renders a 44-digit boleto barcode as a PNG image
+func WritePNG(w io.Writer, code string, options Options) error {
+	img, err := Image(code, options)
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
+
+// WriteSVG renders a 44-digit boleto barcode as an SVG document with one rect per bar
+func WriteSVG(w io.Writer, code string, options Options) error {
+	l, err := newLayout(code, options)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		l.width, l.height, l.width, l.height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#fff"/>`, l.width, l.height)
+
+	x := l.quietZone
+
+	for i, width := range l.widths {
+		if i%2 == 0 {
+			fmt.Fprintf(bw, `<rect x="%d" width="%d" height="%d"/>`, x, width, l.height)
+		}
+
+		x += width
+	}
+
+	bw.WriteString("</svg>\n")
+
+	return bw.Flush()
+}
@@ -0,0 +1,221 @@
+package batch
+
+import (
+	"bufio"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+	"github.com/fonini/go-boleto-utils/validator"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Options configures Process
+type Options struct {
+	// Workers is the number of codes parsed and validated concurrently. Defaults to the number of CPUs.
+	Workers int
+	// Parser configures how bank boletos are parsed
+	Parser parser.Options
+}
+
+// Item is the outcome of processing one code. Boleto or Arrecadacao is set when the code
+// could be parsed, and Reason holds the first check that made an invalid code fail.
+type Item struct {
+	Line        int
+	Code        string
+	CodeType    utils.BoletoCodeType
+	BoletoType  utils.BoletoType
+	BankCode    string
+	Boleto      *utils.Boleto
+	Arrecadacao *utils.Arrecadacao
+	Valid       bool
+	Reason      validator.Field
+	Err         error
+
+	index int
+}
+
+// Summary counts the processed codes. ByType and ByBank count every code that could be
+// parsed, ByReason every invalid code.
+type Summary struct {
+	Total    int
+	Valid    int
+	Invalid  int
+	ByType   map[utils.BoletoType]int
+	ByBank   map[string]int
+	ByReason map[validator.Field]int
+}
+
+func (s *Summary) add(item Item) {
+	s.Total++
+
+	if item.Valid {
+		s.Valid++
+	} else {
+		s.Invalid++
+		s.ByReason[item.Reason]++
+	}
+
+	if item.BoletoType != "" {
+		s.ByType[item.BoletoType]++
+	}
+
+	if item.BankCode != "" {
+		s.ByBank[item.BankCode]++
+	}
+}
+
+type job struct {
+	index int
+	line  int
+	code  string
+}
+
+// Process reads codes from r, one per line, parses and validates them with a bounded pool of
+// workers and calls fn with each item in input order. Blank lines are skipped. Processing
+// stops at the first error returned by fn or by r.
+func Process(r io.Reader, options Options, fn func(Item) error) (*Summary, error) {
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan job)
+	results := make(chan Item)
+	done := make(chan struct{})
+
+	// tokens bounds the codes read but not yet handed to fn, so a slow code
+	// cannot make the reordering buffer grow without limit
+	tokens := make(chan struct{}, workers*4)
+
+	var readErr error
+
+	go func() {
+		defer close(jobs)
+
+		scanner := bufio.NewScanner(r)
+		line, index := 0, 0
+
+		for scanner.Scan() {
+			line++
+
+			code := strings.TrimSpace(scanner.Text())
+			if code == "" {
+				continue
+			}
+
+			select {
+			case tokens <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			select {
+			case jobs <- job{index: index, line: line, code: code}:
+			case <-done:
+				return
+			}
+
+			index++
+		}
+
+		readErr = scanner.Err()
+	}()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				select {
+				case results <- process(j, options.Parser):
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	summary := &Summary{
+		ByType:   map[utils.BoletoType]int{},
+		ByBank:   map[string]int{},
+		ByReason: map[validator.Field]int{},
+	}
+
+	pending := map[int]Item{}
+	next := 0
+
+	var err error
+
+	for item := range results {
+		if err != nil {
+			continue
+		}
+
+		pending[item.index] = item
+
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			delete(pending, next)
+			next++
+			<-tokens
+
+			summary.add(ready)
+
+			if err = fn(ready); err != nil {
+				close(done)
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		return summary, err
+	}
+
+	return summary, readErr
+}
+
+func process(j job, options parser.Options) Item {
+	item := Item{Line: j.line, Code: j.code, index: j.index}
+
+	result := validator.ValidateDetailed(j.code)
+
+	item.CodeType = result.CodeType
+	item.Valid = result.Valid()
+	item.Err = result.Err()
+
+	for _, failure := range result.Failures() {
+		if failure.Field != validator.FieldBank {
+			item.Reason = failure.Field
+			break
+		}
+	}
+
+	if parser.IsArrecadacao(j.code) {
+		if arrecadacao, err := parser.ParseArrecadacao(j.code); err == nil {
+			item.Arrecadacao = arrecadacao
+			item.BoletoType = arrecadacao.Segment
+		}
+	} else if boleto, err := parser.ParseWithOptions(j.code, options); err == nil {
+		item.Boleto = boleto
+		item.BankCode = boleto.IssuerBankCode
+		item.BoletoType = parser.GetBoletoType(j.code)
+	}
+
+	return item
+}
@@ -0,0 +1,104 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/utils"
+	"github.com/fonini/go-boleto-utils/validator"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var codes = []struct {
+	code       string
+	valid      bool
+	reason     validator.Field
+	boletoType utils.BoletoType
+	bankCode   string
+}{
+	{"34191.09263 64672.997190 72734.800005 1 99060000000500", true, "", utils.Bank, "341"},
+	{"34191.75124 34567.871230 41234.560005 8 92850000026035", false, validator.FieldGeneralCheckDigit, utils.Bank, "341"},
+	{"74898992100000845361121577703702280000282105", true, "", utils.Bank, "748"},
+	{"826700000035 645607980002 010002351038 822024116714", true, "", utils.Sanitation, ""},
+	{"846800000008 550000791008 011193989719 924101544345", true, "", utils.Telecommunications, ""},
+	{"123", false, validator.FieldLength, "", ""},
+}
+
+func TestValues_Process(t *testing.T) {
+	var input strings.Builder
+	var want []Item
+
+	for i := 0; i < 500; i++ {
+		c := codes[i%len(codes)]
+
+		fmt.Fprintf(&input, "%s\n", c.code)
+		want = append(want, Item{Line: 2*i + 1, Code: c.code, Valid: c.valid, Reason: c.reason, BoletoType: c.boletoType, BankCode: c.bankCode})
+
+		input.WriteString("\n")
+	}
+
+	for _, workers := range []int{0, 1, 8} {
+		var got []Item
+
+		summary, err := Process(strings.NewReader(input.String()), Options{Workers: workers}, func(item Item) error {
+			got = append(got, Item{Line: item.Line, Code: item.Code, Valid: item.Valid, Reason: item.Reason, BoletoType: item.BoletoType, BankCode: item.BankCode})
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Process(workers=%d) returned %v", workers, err)
+		}
+
+		if diff := cmp.Diff(want, got, cmp.AllowUnexported(Item{})); diff != "" {
+			t.Errorf("Process(workers=%d) items mismatch (-want +got):\n%s", workers, diff)
+		}
+
+		wantSummary := &Summary{
+			Total:   500,
+			Valid:   333,
+			Invalid: 167,
+			ByType: map[utils.BoletoType]int{
+				utils.Bank:               251,
+				utils.Sanitation:         83,
+				utils.Telecommunications: 83,
+			},
+			ByBank: map[string]int{
+				"341": 168,
+				"748": 83,
+			},
+			ByReason: map[validator.Field]int{
+				validator.FieldGeneralCheckDigit: 84,
+				validator.FieldLength:            83,
+			},
+		}
+
+		if diff := cmp.Diff(wantSummary, summary); diff != "" {
+			t.Errorf("Process(workers=%d) summary mismatch (-want +got):\n%s", workers, diff)
+		}
+	}
+}
+
+func TestValues_ProcessStops(t *testing.T) {
+	stop := errors.New("stop")
+	input := strings.Repeat(codes[0].code+"\n", 1000)
+
+	calls := 0
+
+	_, err := Process(strings.NewReader(input), Options{Workers: 4}, func(item Item) error {
+		calls++
+		if calls == 10 {
+			return stop
+		}
+		return nil
+	})
+
+	if !errors.Is(err, stop) {
+		t.Errorf("Process returned %v, want %v", err, stop)
+	}
+
+	if calls != 10 {
+		t.Errorf("Process called fn %d times after it failed, want 10", calls)
+	}
+}
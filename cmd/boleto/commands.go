@@ -0,0 +1,483 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/batch"
+	"github.com/fonini/go-boleto-utils/generator"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+	"github.com/fonini/go-boleto-utils/validator"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+const usage = `Usage: boleto <command> [flags] [code ...]
+
+Commands:
+  parse     print the fields of each code
+  validate  check the digits of each code
+  convert   convert digitable lines to barcodes and barcodes to digitable lines
+  type      print the code type and boleto type of each code
+  generate  build a barcode and digitable line from its fields
+  batch     validate a file of codes, one per line, and summarize the results
+
+Codes are read from the arguments or, when none are given, from standard input,
+one per line. Run "boleto <command> -h" for the flags of a command.
+`
+
+// codeCommand turns a code into the values of an output record and reports whether it succeeded
+type codeCommand struct {
+	columns []string
+	run     func(code string) (map[string]any, bool)
+}
+
+var codeCommands = map[string]codeCommand{
+	"parse": {
+		columns: []string{
+			"code", "code_type", "boleto_type", "bank_code", "bank_name", "currency", "due_date", "amount",
+			"value_identifier", "company_id", "free_field", "agency", "account", "wallet", "agreement", "our_number", "error",
+		},
+		run: parseCode,
+	},
+	"validate": {
+		columns: []string{"code", "code_type", "valid", "error", "failures"},
+		run:     validateCode,
+	},
+	"convert": {
+		columns: []string{"code", "barcode", "digitable_line", "error"},
+		run:     convertCode,
+	},
+	"type": {
+		columns: []string{"code", "code_type", "boleto_type", "error"},
+		run:     typeCode,
+	},
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	case "generate":
+		return runGenerate(args[1:], stdout, stderr)
+	case "batch":
+		return runBatch(args[1:], stdin, stdout, stderr)
+	}
+
+	command, ok := codeCommands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "boleto: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "text", "output format: text, json or csv")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+
+	p, err := newPrinter(*format, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "boleto: %v\n", err)
+		return exitUsage
+	}
+
+	status := exitOK
+
+	err = eachCode(flags.Args(), stdin, func(code string) error {
+		values, ok := command.run(code)
+		if !ok {
+			status = exitFailure
+		}
+
+		return p.print(newRecord(command.columns, values))
+	})
+
+	if err == nil {
+		err = p.flush()
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "boleto: %v\n", err)
+		return exitFailure
+	}
+
+	return status
+}
+
+// eachCode calls fn for every code in args or, when args is empty, for every non-blank line of r
+func eachCode(args []string, r io.Reader, fn func(code string) error) error {
+	if len(args) > 0 {
+		for _, code := range args {
+			if err := fn(code); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		code := strings.TrimSpace(scanner.Text())
+		if code == "" {
+			continue
+		}
+
+		if err := fn(code); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func newRecord(columns []string, values map[string]any) []field {
+	record := make([]field, len(columns))
+
+	for i, column := range columns {
+		record[i] = field{name: column, value: values[column]}
+	}
+
+	return record
+}
+
+func parseCode(code string) (map[string]any, bool) {
+	values := map[string]any{"code": code}
+
+	if parser.IsArrecadacao(code) {
+		arrecadacao, err := parser.ParseArrecadacao(code)
+		if err != nil {
+			values["error"] = err.Error()
+			return values, false
+		}
+
+		values["code_type"] = arrecadacao.CodeType
+		values["boleto_type"] = arrecadacao.Segment
+		values["amount"] = arrecadacao.Amount
+		values["value_identifier"] = arrecadacao.ValueIdentifier
+		values["company_id"] = arrecadacao.CompanyID
+		values["free_field"] = arrecadacao.FreeField
+
+		return values, true
+	}
+
+	boleto, err := parser.Parse(code)
+	if err != nil {
+		values["error"] = err.Error()
+		return values, false
+	}
+
+	values["code_type"] = boleto.CodeType
+	values["boleto_type"] = parser.GetBoletoType(code)
+	values["bank_code"] = boleto.IssuerBankCode
+	values["bank_name"] = boleto.IssuerBankName
+	values["currency"] = boleto.Currency
+	values["due_date"] = boleto.DueDate
+	values["amount"] = boleto.Amount
+	values["free_field"] = boleto.IssuerReserved1 + boleto.IssuerReserved2 + boleto.IssuerReserved3
+
+	if boleto.FreeField != nil {
+		values["agency"] = boleto.FreeField.Agency
+		values["account"] = boleto.FreeField.Account + boleto.FreeField.AccountDigit
+		values["wallet"] = boleto.FreeField.Wallet
+		values["agreement"] = boleto.FreeField.Agreement
+		values["our_number"] = boleto.FreeField.OurNumber + boleto.FreeField.OurNumberDigit
+	}
+
+	return values, true
+}
+
+func validateCode(code string) (map[string]any, bool) {
+	result := validator.ValidateDetailed(code)
+
+	values := map[string]any{
+		"code":      code,
+		"code_type": result.CodeType,
+		"valid":     result.Valid(),
+	}
+
+	if err := result.Err(); err != nil {
+		values["error"] = err.Error()
+	}
+
+	var failures []string
+	for _, failure := range result.Failures() {
+		description := string(failure.Field)
+
+		if failure.Expected != "" {
+			description += fmt.Sprintf(" expected %s, got %s", failure.Expected, failure.Actual)
+		} else if failure.Actual != "" {
+			description += fmt.Sprintf(" got %s", failure.Actual)
+		}
+
+		if failure.Offset >= 0 {
+			description += fmt.Sprintf(" at offset %d", failure.Offset)
+		}
+
+		failures = append(failures, description)
+	}
+	values["failures"] = strings.Join(failures, "; ")
+
+	return values, result.Valid()
+}
+
+func convertCode(code string) (map[string]any, bool) {
+	values := map[string]any{"code": code}
+	digits := utils.OnlyNumbers(code)
+
+	codeType, err := parser.GetCodeType(digits)
+	if err != nil {
+		values["error"] = err.Error()
+		return values, false
+	}
+
+	if codeType == parser.Barcode {
+		if err := validator.Check(digits); err != nil {
+			values["error"] = err.Error()
+			return values, false
+		}
+
+		values["barcode"] = digits
+		values["digitable_line"] = parser.FormatDigitableLine(parser.ConvertBarcodeToDigitableLine(digits))
+
+		return values, true
+	}
+
+	barcode, err := parser.ConvertDigitableLineToBarcode(digits)
+	if err != nil {
+		values["error"] = err.Error()
+		return values, false
+	}
+
+	values["barcode"] = barcode
+	values["digitable_line"] = parser.FormatDigitableLine(digits)
+
+	return values, true
+}
+
+func typeCode(code string) (map[string]any, bool) {
+	values := map[string]any{"code": code}
+
+	codeType, err := parser.GetCodeType(code)
+	values["code_type"] = codeType
+
+	if err != nil {
+		values["error"] = err.Error()
+		return values, false
+	}
+
+	values["boleto_type"] = parser.GetBoletoType(code)
+
+	return values, true
+}
+
+func runGenerate(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	format := flags.String("format", "text", "output format: text, json or csv")
+	bankCode := flags.String("bank", "", "3-digit bank code of a bank boleto")
+	currency := flags.Int("currency", generator.RealCurrency, "currency code of a bank boleto")
+	dueDate := flags.String("due", "", "due date of a bank boleto (YYYY-MM-DD), empty for no due date")
+	segment := flags.String("segment", "", "segment of an arrecadação code, e.g. SANITATION or CITY_HALLS")
+	valueIdentifier := flags.Int("value-identifier", generator.EffectiveValueMod10, "value identifier of an arrecadação code (6, 7, 8 or 9)")
+	companyID := flags.String("company", "", "FEBRABAN company code or CNPJ root of an arrecadação code")
+	amount := flags.String("amount", "0", "amount in reais, e.g. 1160.37 or 1.160,37")
+	freeField := flags.String("free-field", "", "free field (25 digits, or 21 for CNPJ arrecadação codes)")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	p, err := newPrinter(*format, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "boleto: %v\n", err)
+		return exitUsage
+	}
+
+	money, err := utils.ParseMoney(*amount)
+	if err != nil {
+		fmt.Fprintf(stderr, "boleto: %v\n", err)
+		return exitUsage
+	}
+
+	var result *generator.Result
+
+	if *segment != "" {
+		result, err = generator.GenerateArrecadacao(generator.ArrecadacaoRequest{
+			Segment:         utils.BoletoType(strings.ToUpper(*segment)),
+			ValueIdentifier: *valueIdentifier,
+			Amount:          money,
+			CompanyID:       *companyID,
+			FreeField:       *freeField,
+		})
+	} else {
+		request := generator.Request{
+			BankCode:  *bankCode,
+			Currency:  *currency,
+			Amount:    money,
+			FreeField: *freeField,
+		}
+
+		if *dueDate != "" {
+			request.DueDate, err = time.Parse("2006-01-02", *dueDate)
+			if err != nil {
+				fmt.Fprintf(stderr, "boleto: invalid due date %q\n", *dueDate)
+				return exitUsage
+			}
+		}
+
+		result, err = generator.Generate(request)
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "boleto: %v\n", err)
+		return exitFailure
+	}
+
+	err = p.print([]field{{"barcode", result.Barcode}, {"digitable_line", result.DigitableLine}})
+	if err == nil {
+		err = p.flush()
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "boleto: %v\n", err)
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func runBatch(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	format := flags.String("format", "text", "output format: text, json or csv")
+	workers := flags.Int("workers", 0, "number of concurrent workers, 0 for one per CPU")
+	summaryOnly := flags.Bool("summary", false, "print only the summary, not a record per code")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "boleto: batch reads a single file")
+		return exitUsage
+	}
+
+	p, err := newPrinter(*format, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "boleto: %v\n", err)
+		return exitUsage
+	}
+
+	input := stdin
+
+	if flags.NArg() == 1 {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintf(stderr, "boleto: %v\n", err)
+			return exitFailure
+		}
+		defer file.Close()
+
+		input = file
+	}
+
+	summary, err := batch.Process(input, batch.Options{Workers: *workers}, func(item batch.Item) error {
+		if *summaryOnly {
+			return nil
+		}
+
+		return p.print(batchRecord(item))
+	})
+
+	if err == nil {
+		if *summaryOnly {
+			err = p.print(summaryRecord(summary))
+		} else {
+			err = printSummary(stderr, summary)
+		}
+	}
+
+	if err == nil {
+		err = p.flush()
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "boleto: %v\n", err)
+		return exitFailure
+	}
+
+	if summary.Invalid > 0 {
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func batchRecord(item batch.Item) []field {
+	record := []field{
+		{"line", item.Line},
+		{"code", item.Code},
+		{"code_type", item.CodeType},
+		{"boleto_type", item.BoletoType},
+		{"bank_code", item.BankCode},
+		{"valid", item.Valid},
+		{"reason", item.Reason},
+		{"error", nil},
+	}
+
+	if item.Err != nil {
+		record[7].value = item.Err.Error()
+	}
+
+	return record
+}
+
+func summaryRecord(summary *batch.Summary) []field {
+	byType := map[string]int{}
+	for boletoType, count := range summary.ByType {
+		byType[string(boletoType)] = count
+	}
+
+	byReason := map[string]int{}
+	for reason, count := range summary.ByReason {
+		byReason[string(reason)] = count
+	}
+
+	return []field{
+		{"total", summary.Total},
+		{"valid", summary.Valid},
+		{"invalid", summary.Invalid},
+		{"by_type", byType},
+		{"by_bank", summary.ByBank},
+		{"by_reason", byReason},
+	}
+}
+
+// printSummary writes the summary as text, so it can go to standard error next to records in any format
+func printSummary(w io.Writer, summary *batch.Summary) error {
+	p := &textPrinter{w: w}
+
+	return p.print(summaryRecord(summary))
+}
@@ -0,0 +1,33 @@
+// Command boleto parses, validates, converts and generates boleto barcodes and digitable lines.
+//
+// Usage:
+//
+//	boleto <command> [flags] [code ...]
+//
+// The commands are:
+//
+//	parse     print the fields of each code
+//	validate  check the digits of each code
+//	convert   convert digitable lines to barcodes and barcodes to digitable lines
+//	type      print the code type and boleto type of each code
+//	generate  build a barcode and digitable line from its fields
+//	batch     validate a file of codes, one per line, and summarize the results
+//
+// Codes are read from the arguments or, when none are given, from standard input, one per
+// line. The -format flag selects text (default), json (one object per line) or csv output.
+//
+// The batch command reads a file, or standard input, with one code per line and validates the
+// codes concurrently (-workers), printing a record per code in input order followed by a summary
+// on standard error. With -summary only the summary is printed, in the selected format.
+//
+// The exit status is 0 when every code is valid, 1 when any code fails to parse, validate
+// or convert, and 2 on usage errors.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestValues_Run(t *testing.T) {
+	tests := []struct {
+		args   []string
+		stdin  string
+		want   string
+		status int
+	}{
+		{[]string{"validate", "34191.09263 64672.997190 72734.800005 1 99060000000500"},
+			"",
+			"code: 34191.09263 64672.997190 72734.800005 1 99060000000500\ncode_type: DIGITABLE_LINE\nvalid: true\n",
+			exitOK,
+		},
+		{[]string{"validate", "-format", "json"},
+			"34191.75124 34567.871230 41234.560005 8 92850000026035\n\n34191990600000005001092664672997197273480000\n",
+			`{"code":"34191.75124 34567.871230 41234.560005 8 92850000026035","code_type":"DIGITABLE_LINE","valid":false,"error":"invalid general check digit","failures":"GENERAL_CHECK_DIGIT expected 7, got 8 at offset 38"}` + "\n" +
+				`{"code":"34191990600000005001092664672997197273480000","code_type":"BARCODE","valid":true}` + "\n",
+			exitFailure,
+		},
+		{[]string{"convert", "-format", "csv", "34191990600000005001092664672997197273480000", "85860000000 4 83740385242 0 43070124241 5 85141630306 0"},
+			"",
+			"code,barcode,digitable_line,error\n" +
+				"34191990600000005001092664672997197273480000,34191990600000005001092664672997197273480000,34191.09263 64672.997190 72734.800005 1 99060000000500,\n" +
+				"85860000000 4 83740385242 0 43070124241 5 85141630306 0,85860000000837403852424307012424185141630306,85860000000-4 83740385242-0 43070124241-5 85141630306-0,\n",
+			exitOK,
+		},
+		{[]string{"convert", "-format", "csv", "34191990600000006001092664672997197273480000"},
+			"",
+			"code,barcode,digitable_line,error\n34191990600000006001092664672997197273480000,,,invalid general check digit\n",
+			exitFailure,
+		},
+		{[]string{"parse", "-format", "json", "74898992100000845361121577703702280000282105"},
+			"",
+			`{"code":"74898992100000845361121577703702280000282105","code_type":"BARCODE","boleto_type":"BANK","bank_code":"748","bank_name":"Banco Cooperativo Sicredi S.A.","currency":9,"due_date":"2024-12-05","amount":845.36,` +
+				`"free_field":"1121577703702280000282105","agency":"0228","wallet":"1","agreement":"00282","our_number":"215777037"}` + "\n",
+			exitOK,
+		},
+		{[]string{"parse", "826700000035 645607980002 010002351038 822024116714", "123"},
+			"",
+			"code: 826700000035 645607980002 010002351038 822024116714\ncode_type: DIGITABLE_LINE\nboleto_type: SANITATION\namount: R$ 364,56\n" +
+				"value_identifier: 6\ncompany_id: 0798\nfree_field: 0000100023510382202411671\n\n" +
+				"code: 123\nerror: parse \"123\": Length: unknown code\n",
+			exitFailure,
+		},
+		{[]string{"type", "-format", "csv", "846800000008 550000791008 011193989719 924101544345"},
+			"",
+			"code,code_type,boleto_type,error\n846800000008 550000791008 011193989719 924101544345,DIGITABLE_LINE,TELECOMMUNICATIONS,\n",
+			exitOK,
+		},
+		{[]string{"generate", "-bank", "341", "-due", "2024-11-20", "-amount", "5,00", "-free-field", "1092664672997197273480000"},
+			"",
+			"barcode: 34191990600000005001092664672997197273480000\ndigitable_line: 34191.09263 64672.997190 72734.800005 1 99060000000500\n",
+			exitOK,
+		},
+		{[]string{"generate", "-format", "json", "-segment", "sanitation", "-amount", "364.56", "-company", "0798", "-free-field", "0000100023510382202411671"},
+			"",
+			`{"barcode":"82670000003645607980000100023510382202411671","digitable_line":"82670000003-5 64560798000-2 01000235103-8 82202411671-4"}` + "\n",
+			exitOK,
+		},
+		{[]string{"generate", "-bank", "34", "-free-field", "1092664672997197273480000"},
+			"",
+			"",
+			exitFailure,
+		},
+		{[]string{"batch", "-workers", "2", "-format", "csv"},
+			"34191.09263 64672.997190 72734.800005 1 99060000000500\n\n123\n826700000035 645607980002 010002351038 822024116714\n",
+			"line,code,code_type,boleto_type,bank_code,valid,reason,error\n" +
+				"1,34191.09263 64672.997190 72734.800005 1 99060000000500,DIGITABLE_LINE,BANK,341,true,,\n" +
+				"3,123,UNKNOWN,,,false,LENGTH,invalid code\n" +
+				"4,826700000035 645607980002 010002351038 822024116714,DIGITABLE_LINE,SANITATION,,true,,\n",
+			exitFailure,
+		},
+		{[]string{"batch", "-format", "json"},
+			"34191.09263 64672.997190 72734.800005 1 99060000000500\n123\n",
+			`{"line":1,"code":"34191.09263 64672.997190 72734.800005 1 99060000000500","code_type":"DIGITABLE_LINE","boleto_type":"BANK","bank_code":"341","valid":true}` + "\n" +
+				`{"line":2,"code":"123","code_type":"UNKNOWN","valid":false,"reason":"LENGTH","error":"invalid code"}` + "\n",
+			exitFailure,
+		},
+		{[]string{"batch", "-summary", "-format", "json"},
+			"34191.09263 64672.997190 72734.800005 1 99060000000500\n74898992100000845361121577703702280000282105\n",
+			`{"total":2,"valid":2,"invalid":0,"by_type":{"BANK":2},"by_bank":{"341":1,"748":1},"by_reason":{}}` + "\n",
+			exitOK,
+		},
+		{[]string{"validate", "-format", "xml", "123"},
+			"",
+			"",
+			exitUsage,
+		},
+		{[]string{"unknown"},
+			"",
+			"",
+			exitUsage,
+		},
+		{nil,
+			"",
+			"",
+			exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+
+		status := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+		if status != tt.status {
+			t.Errorf("run(%q) = %d, want %d (stderr: %s)", tt.args, status, tt.status, stderr.String())
+		}
+
+		if stdout.String() != tt.want {
+			t.Errorf("run(%q) printed:\n%s\nwant:\n%s", tt.args, stdout.String(), tt.want)
+		}
+	}
+}
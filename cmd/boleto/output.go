@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/utils"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// field is a named value of an output record. Records of a command always have the
+// same fields, in the same order, so they can be written as CSV rows.
+type field struct {
+	name  string
+	value any
+}
+
+// printer writes output records in one of the supported formats
+type printer interface {
+	print(record []field) error
+	flush() error
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "text":
+		return &textPrinter{w: w}, nil
+	case "json":
+		return &jsonPrinter{w: w}, nil
+	case "csv":
+		return &csvPrinter{w: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// textPrinter writes one "name: value" line per non-empty field, with a blank line between records
+type textPrinter struct {
+	w       io.Writer
+	printed bool
+}
+
+func (p *textPrinter) print(record []field) error {
+	if p.printed {
+		if _, err := fmt.Fprintln(p.w); err != nil {
+			return err
+		}
+	}
+	p.printed = true
+
+	for _, f := range record {
+		value := formatText(f.value)
+		if value == "" {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(p.w, "%s: %s\n", f.name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *textPrinter) flush() error {
+	return nil
+}
+
+// jsonPrinter writes one JSON object per record and line, omitting empty fields
+type jsonPrinter struct {
+	w io.Writer
+}
+
+func (p *jsonPrinter) print(record []field) error {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for _, f := range record {
+		value := formatJSON(f.value)
+		if value == nil {
+			continue
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(f.name)
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(encoded)
+	}
+
+	buf.WriteString("}\n")
+
+	_, err := p.w.Write(buf.Bytes())
+
+	return err
+}
+
+func (p *jsonPrinter) flush() error {
+	return nil
+}
+
+// csvPrinter writes a header with the field names of the first record, then one row per record
+type csvPrinter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (p *csvPrinter) print(record []field) error {
+	if !p.header {
+		p.header = true
+
+		names := make([]string, len(record))
+		for i, f := range record {
+			names[i] = f.name
+		}
+
+		if err := p.w.Write(names); err != nil {
+			return err
+		}
+	}
+
+	values := make([]string, len(record))
+	for i, f := range record {
+		values[i] = formatCSV(f.value)
+	}
+
+	return p.w.Write(values)
+}
+
+func (p *csvPrinter) flush() error {
+	p.w.Flush()
+	return p.w.Error()
+}
+
+func formatText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format("2006-01-02")
+	case map[string]int:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		counts := make([]string, len(keys))
+		for i, key := range keys {
+			counts[i] = fmt.Sprintf("%s=%d", key, v[key])
+		}
+
+		return strings.Join(counts, " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatJSON(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case utils.Money:
+		return json.Number(v.Decimal())
+	case time.Time:
+		return v.Format("2006-01-02")
+	}
+
+	// strings and named string types such as utils.BoletoType
+	if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+		if v.String() == "" {
+			return nil
+		}
+		return v.String()
+	}
+
+	return value
+}
+
+func formatCSV(value any) string {
+	switch v := value.(type) {
+	case utils.Money:
+		return v.Decimal()
+	default:
+		return formatText(value)
+	}
+}
@@ -0,0 +1,239 @@
+// Package cnab holds the types shared by the FEBRABAN CNAB layouts, the fixed-width files
+// companies exchange with their banks to register boletos (remessa) and learn what happened
+// to them (retorno). The layouts themselves are written and read by the cnab240 and cnab400
+// packages.
+package cnab
+
+import (
+	"github.com/fonini/go-boleto-utils/utils"
+	"time"
+)
+
+// Movement is the instruction a remessa sends for a title (código de movimento)
+type Movement string
+
+const (
+	MovementRegister        Movement = "01"
+	MovementWriteOff        Movement = "02"
+	MovementGrantRebate     Movement = "04"
+	MovementCancelRebate    Movement = "05"
+	MovementChangeDueDate   Movement = "06"
+	MovementProtest         Movement = "09"
+	MovementCancelProtest   Movement = "10"
+	MovementKeepWithProtest Movement = "11"
+)
+
+// Kind is the kind of document a title stands for (espécie do título)
+type Kind string
+
+const (
+	KindDuplicataMercantil Kind = "02"
+	KindDuplicataServico   Kind = "04"
+	KindNotaPromissoria    Kind = "12"
+	KindRecibo             Kind = "17"
+	KindOther              Kind = "99"
+)
+
+// Charge codes of interest, discounts and fines. A zero code means none is charged.
+const (
+	ChargeNone    = 0
+	ChargeAmount  = 1
+	ChargePercent = 2
+)
+
+// Account is the beneficiary's collection account at the bank (conta de cobrança). Agreement
+// is the convênio or código do beneficiário the bank assigned, Wallet the carteira and
+// Variation the variação de carteira some banks add to it.
+type Account struct {
+	Agency      string
+	AgencyDigit string
+	Number      string
+	Digit       string
+	Agreement   string
+	Wallet      string
+	Variation   string
+}
+
+// Company is the beneficiary (beneficiário) that sends the remessa
+type Company struct {
+	Name     string
+	Document string
+	Account  Account
+}
+
+// Payer is the payer (pagador) of a title, or the guarantor (sacador/avalista)
+type Payer struct {
+	Name       string
+	Document   string
+	Address    string
+	District   string
+	PostalCode string
+	City       string
+	State      string
+	Email      string
+}
+
+// Charge is interest, a discount or a fine: a ChargeAmount charges Value centavos (per day,
+// for interest), a ChargePercent charges Rate percent (per month, for interest)
+type Charge struct {
+	Code  int
+	Date  time.Time
+	Value utils.Money
+	Rate  float64
+}
+
+// Title is a boleto registered with the bank (título de cobrança). OurNumber and
+// OurNumberDigit are the nosso número and its check digit as printed on the boleto;
+// CompanyID is the company's own identifier, returned as is in the retorno.
+type Title struct {
+	Movement       Movement
+	OurNumber      string
+	OurNumberDigit string
+	DocumentNumber string
+	CompanyID      string
+	Kind           Kind
+	Accepted       bool
+	IssueDate      time.Time
+	DueDate        time.Time
+	Amount         utils.Money
+	Rebate         utils.Money
+	IOF            utils.Money
+	Interest       Charge
+	Fine           Charge
+	Discounts      []Charge
+	ProtestDays    int
+	WriteOffDays   int
+	Payer          Payer
+	Guarantor      *Payer
+	Messages       []string
+	Pix            *Pix
+}
+
+// Pix identifies the PIX charge printed on a boleto híbrido
+type Pix struct {
+	KeyType int
+	Key     string
+	TxID    string
+}
+
+// PIX key types
+const (
+	PixKeyCPF = iota + 1
+	PixKeyCNPJ
+	PixKeyPhone
+	PixKeyEmail
+	PixKeyRandom
+)
+
+// Remittance is a remessa: the titles a company sends to one bank in a file. Sequence is the
+// file sequence number (NSA) the bank uses to detect missing or repeated files.
+type Remittance struct {
+	BankCode  string
+	Company   Company
+	Sequence  int
+	CreatedAt time.Time
+	Titles    []Title
+}
+
+// PersonType returns the FEBRABAN code of a CPF (1) or CNPJ (2) document, or 0 when the
+// document is empty
+func PersonType(document string) int {
+	switch digits := utils.OnlyNumbers(document); {
+	case digits == "":
+		return 0
+	case len(digits) <= 11:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Header identifies the company, bank and sequence of a CNAB file
+type Header struct {
+	BankCode  string
+	Company   Company
+	Sequence  int
+	CreatedAt time.Time
+}
+
+// Occurrence is the event a retorno reports for a title (código de ocorrência)
+type Occurrence string
+
+const (
+	OccurrenceRegistered          Occurrence = "02"
+	OccurrenceRejected            Occurrence = "03"
+	OccurrencePaid                Occurrence = "06"
+	OccurrenceWrittenOff          Occurrence = "09"
+	OccurrenceRebateGranted       Occurrence = "12"
+	OccurrenceRebateCancelled     Occurrence = "13"
+	OccurrenceDueDateChanged      Occurrence = "14"
+	OccurrencePaidAfterWriteOff   Occurrence = "17"
+	OccurrenceProtestRequested    Occurrence = "19"
+	OccurrenceProtestCancelled    Occurrence = "20"
+	OccurrenceSentToNotary        Occurrence = "23"
+	OccurrenceProtested           Occurrence = "25"
+	OccurrenceInstructionRejected Occurrence = "26"
+	OccurrenceTariff              Occurrence = "28"
+	OccurrenceChangeRejected      Occurrence = "30"
+)
+
+var occurrences = map[Occurrence]string{
+	OccurrenceRegistered:          "Entrada confirmada",
+	OccurrenceRejected:            "Entrada rejeitada",
+	OccurrencePaid:                "Liquidação",
+	OccurrenceWrittenOff:          "Baixa",
+	OccurrenceRebateGranted:       "Confirmação de abatimento",
+	OccurrenceRebateCancelled:     "Confirmação de cancelamento de abatimento",
+	OccurrenceDueDateChanged:      "Confirmação de alteração de vencimento",
+	OccurrencePaidAfterWriteOff:   "Liquidação após baixa",
+	OccurrenceProtestRequested:    "Confirmação de instrução de protesto",
+	OccurrenceProtestCancelled:    "Confirmação de sustação de protesto",
+	OccurrenceSentToNotary:        "Remessa a cartório",
+	OccurrenceProtested:           "Protestado e baixado",
+	OccurrenceInstructionRejected: "Instrução rejeitada",
+	OccurrenceTariff:              "Débito de tarifas/custas",
+	OccurrenceChangeRejected:      "Alteração de dados rejeitada",
+}
+
+// Description returns the FEBRABAN description of the occurrence, or an empty string for
+// bank-specific codes
+func (o Occurrence) Description() string {
+	return occurrences[o]
+}
+
+// Paid reports whether the occurrence settles the title
+func (o Occurrence) Paid() bool {
+	return o == OccurrencePaid || o == OccurrencePaidAfterWriteOff
+}
+
+// Return is a title reported in a retorno. Line is the line of the file the title starts
+// at, and Barcode the barcode of the boleto when the bank's free field can be rebuilt from
+// the retorno.
+type Return struct {
+	Line             int
+	Occurrence       Occurrence
+	Reasons          []string
+	BankCode         string
+	Account          Account
+	OurNumber        string
+	OurNumberDigit   string
+	DocumentNumber   string
+	CompanyID        string
+	DueDate          time.Time
+	Amount           utils.Money
+	CollectingBank   string
+	CollectingAgency string
+	Payer            Payer
+	Tariff           utils.Money
+	Interest         utils.Money
+	Discount         utils.Money
+	Rebate           utils.Money
+	IOF              utils.Money
+	PaidAmount       utils.Money
+	CreditedAmount   utils.Money
+	OtherExpenses    utils.Money
+	OtherCredits     utils.Money
+	OccurrenceDate   time.Time
+	CreditDate       time.Time
+	Barcode          string
+}
@@ -0,0 +1,134 @@
+// Package cnab240 writes and reads the FEBRABAN CNAB 240 layout for cobrança: files of
+// 240-position records made of a file header, batches of segments and a file trailer.
+//
+// The records are written and read with the febraban-240 layouts of the layout package, or
+// with the layouts of the bank's variant. Banco do Brasil, Caixa, Bradesco, Sicredi and
+// Sicoob have bundled variants.
+package cnab240
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/cnab/layout"
+	"sync"
+)
+
+// RecordLength is the length of every record, not counting the line break
+const RecordLength = 240
+
+// Record types (tipo de registro), at position 8
+const (
+	FileHeader   = '0'
+	BatchHeader  = '1'
+	Detail       = '3'
+	BatchTrailer = '5'
+	FileTrailer  = '9'
+)
+
+// Layout versions of the FEBRABAN file and cobrança batch, the defaults of the febraban-240
+// layouts
+const (
+	FebrabanLayout = "087"
+	FebrabanBatch  = "045"
+)
+
+var (
+	// ErrInvalidRemittance is returned when a remessa lacks a field every file needs
+	ErrInvalidRemittance = errors.New("invalid CNAB 240 remittance")
+	// ErrInvalidTitle is returned when a title can't be registered
+	ErrInvalidTitle = errors.New("invalid CNAB 240 title")
+)
+
+// Bank is the CNAB 240 variant of a bank: the layouts of its remessa and retorno, which
+// differ from FEBRABAN's in the layout versions, the agreement and account fields of the
+// headers and the nosso número of segments P and T, and how the free field of a boleto is
+// rebuilt from a retorno.
+//
+// The layouts name their fields like the febraban-240 ones. The writer fills "agreement",
+// "agency", "agency digit", "account", "account digit", "wallet" and "variation" from the
+// account in the headers and segment P, and "our number", "our number digit" and "our number
+// with digit", the two joined, in segment P. The reader reads the same names from the
+// headers and segment T, the wallet from "wallet code" when there is no "wallet". Fields
+// the file has no value for hold their defaults, the constants of the layout.
+type Bank struct {
+	Code string
+	Name string
+	// Remessa and Retorno are the layouts of the files of the bank, with the records of the
+	// febraban-240 layouts. Lookup sets the FEBRABAN ones when they are nil.
+	Remessa *layout.Layout
+	Retorno *layout.Layout
+	// FreeField rebuilds the free field of the boleto of a title read from segments T and U,
+	// so cnab.Link can set its barcode
+	FreeField func(r *cnab.Return) (string, bool)
+}
+
+var (
+	mu       sync.RWMutex
+	variants = map[string]Bank{
+		"001": bundled("bancodobrasil", Bank{Code: "001"}),
+		"104": bundled("caixa", Bank{Code: "104"}),
+		"237": bundled("bradesco", Bank{Code: "237", FreeField: cnab.BradescoFreeField}),
+		"748": bundled("sicredi", Bank{Code: "748"}),
+		"756": bundled("sicoob", Bank{Code: "756"}),
+	}
+)
+
+// Register sets the variant used for the files of bank.Code, replacing any previous one
+func Register(bank Bank) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	variants[bank.Code] = bank
+}
+
+// Lookup returns the variant registered for bankCode. Banks without one use the FEBRABAN
+// layouts as published.
+func Lookup(bankCode string) (Bank, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	bank, ok := variants[bankCode]
+	if !ok {
+		bank = Bank{Code: bankCode}
+	}
+
+	if bank.Remessa == nil {
+		bank.Remessa, _ = layout.Lookup("febraban-240-remessa")
+	}
+
+	if bank.Retorno == nil {
+		bank.Retorno, _ = layout.Lookup("febraban-240-retorno")
+	}
+
+	return bank, ok
+}
+
+// checked returns l ready to write and read records, or an error when it isn't a valid
+// CNAB 240 layout
+func checked(l *layout.Layout) (*layout.Layout, error) {
+	l, err := l.Checked()
+	if err != nil {
+		return nil, err
+	}
+
+	if l.Length != RecordLength {
+		return nil, fmt.Errorf("%w: %s has %d-position records", layout.ErrInvalidLayout, l.Name, l.Length)
+	}
+
+	return l, nil
+}
+
+// bundled returns bank with the bundled layouts named after name. Banks whose retorno
+// follows FEBRABAN have no retorno layout of their own.
+func bundled(name string, bank Bank) Bank {
+	remessa, _ := layout.Lookup(name + "-240-remessa")
+	if remessa == nil {
+		panic("cnab240: missing bundled layout for " + name)
+	}
+
+	bank.Remessa = remessa
+	bank.Retorno, _ = layout.Lookup(name + "-240-retorno")
+
+	return bank
+}
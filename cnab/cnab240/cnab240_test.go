@@ -0,0 +1,435 @@
+package cnab240
+
+import (
+	"bytes"
+	"errors"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/cnab/internal/cnabtest"
+	"github.com/fonini/go-boleto-utils/cnab/layout"
+	"github.com/fonini/go-boleto-utils/generator"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func remittance(bankCode string) *cnab.Remittance {
+	return cnabtest.Remittance(bankCode,
+		cnab.Account{Agency: "4321", AgencyDigit: "0", Number: "98765", Digit: "4", Agreement: "123456", Wallet: "17", Variation: "19"},
+		cnab.Title{
+			OurNumber:      "123456",
+			OurNumberDigit: "7",
+			DocumentNumber: "NF-1001",
+			CompanyID:      "pedido 1001",
+			DueDate:        time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC),
+			Amount:         116037,
+			Payer:          cnabtest.Payer(),
+		},
+		cnab.Title{
+			OurNumber:   "123457",
+			DueDate:     time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC),
+			Amount:      50000,
+			Kind:        cnab.KindDuplicataServico,
+			Accepted:    true,
+			Interest:    cnab.Charge{Code: cnab.ChargePercent, Date: time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC), Rate: 1},
+			Fine:        cnab.Charge{Code: cnab.ChargePercent, Date: time.Date(2024, 12, 21, 0, 0, 0, 0, time.UTC), Rate: 2},
+			Discounts:   []cnab.Charge{{Code: cnab.ChargeAmount, Date: time.Date(2024, 12, 10, 0, 0, 0, 0, time.UTC), Value: 1000}, {Code: cnab.ChargeAmount, Date: time.Date(2024, 12, 15, 0, 0, 0, 0, time.UTC), Value: 500}},
+			ProtestDays: 5,
+			Payer:       cnab.Payer{Name: "Maria Souza", Document: "98.765.432/0001-10", PostalCode: "70000000", City: "Brasília", State: "DF"},
+			Guarantor:   &cnab.Payer{Name: "Banco Avalista", Document: "11.222.333/0001-81"},
+			Messages:    []string{"Não receber após 30 dias", "Mensagem 4", "Mensagem 5"},
+			Pix:         &cnab.Pix{KeyType: cnab.PixKeyEmail, Key: "financeiro@padaria.com.br", TxID: "BOLETO123457"},
+		},
+	)
+}
+
+func write(t *testing.T, r *cnab.Remittance) []string {
+	return cnabtest.Lines(t, Write, r, RecordLength)
+}
+
+func TestValues_Write(t *testing.T) {
+	lines := write(t, remittance("756"))
+
+	var kinds []string
+	for _, line := range lines {
+		kind := cnabtest.Field(line, 8, 8)
+		if kind == "3" {
+			kind += cnabtest.Field(line, 14, 14)
+		}
+
+		kinds = append(kinds, kind)
+	}
+
+	want := []string{"0", "1", "3P", "3Q", "3P", "3Q", "3R", "3S", "3Y", "5", "9"}
+	if diff := cmp.Diff(want, kinds); diff != "" {
+		t.Fatalf("record types mismatch (-want +got):\n%s", diff)
+	}
+
+	tests := []struct {
+		line       int
+		start, end int
+		want       string
+	}{
+		// file header
+		{0, 1, 8, "75600000"},
+		{0, 18, 32, "212345678000195"},
+		{0, 53, 72, "0432100000000987654 "},
+		{0, 73, 102, "PADARIA PAO QUENTE LTDA       "},
+		{0, 103, 132, "BANCOOB                       "},
+		{0, 143, 171, "11011202408300000000708100000"},
+		// batch header
+		{1, 1, 17, "75600011R01  040 "},
+		{1, 184, 207, "000000071011202400000000"},
+		// first title
+		{2, 9, 17, "00001P 01"},
+		{2, 18, 37, "0432100000000987654 "},
+		{2, 38, 62, "000123456701014     11122"},
+		{2, 63, 77, "NF-1001        "},
+		{2, 78, 100, "20112024000000000116037"},
+		{2, 107, 126, "02N10112024300000000"},
+		{2, 142, 142, "0"},
+		{2, 196, 240, "PEDIDO 1001              3002000090000000000 "},
+		{3, 9, 17, "00002Q 01"},
+		{3, 18, 73, "1000012345678909JOSE DA SILVA                           "},
+		{3, 129, 153, "01310100SAO PAULO      SP"},
+		{3, 154, 169, "0000000000000000"},
+		// second title
+		{4, 9, 13, "00003"},
+		{4, 38, 57, "000012345701014     "},
+		{4, 107, 141, "04A10112024221122024000000000000100"},
+		{4, 142, 165, "110122024000000000001000"},
+		{4, 221, 227, "1052000"},
+		{5, 154, 209, "2011222333000181BANCO AVALISTA                          "},
+		{6, 9, 14, "00005R"},
+		{6, 18, 41, "115122024000000000000500"},
+		{6, 42, 89, "000000000000000000000000221122024000000000000200"},
+		{6, 100, 179, "NAO RECEBER APOS 30 DIAS                MENSAGEM 4                              "},
+		{7, 18, 58, "3MENSAGEM 5                              "},
+		{8, 18, 60, "034FINANCEIRO@PADARIA.COM.BR               "},
+		{8, 98, 132, "BOLETO123457                       "},
+		// trailers
+		{9, 1, 46, "75600015         00000900000200000000000166037"},
+		{10, 1, 35, "75699999         000001000011000000"},
+	}
+
+	for _, tt := range tests {
+		if got := cnabtest.Field(lines[tt.line], tt.start, tt.end); got != tt.want {
+			t.Errorf("line %d, positions %d-%d = %q, want %q", tt.line+1, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestValues_WriteBanks(t *testing.T) {
+	tests := []struct {
+		bankCode   string
+		line       int
+		start, end int
+		want       string
+	}{
+		{"001", 0, 33, 72, "000123456001417019  0432100000000987654 "},
+		{"001", 0, 164, 166, "083"},
+		{"104", 0, 33, 72, "0000000000000000000004321012345600000000"},
+		{"104", 2, 18, 57, "0432101234560000000000014000000000123456"},
+		{"237", 0, 33, 52, "00000000000000123456"},
+		{"237", 2, 38, 57, "01700000000001234567"},
+		{"033", 0, 33, 52, "123456              "},
+		{"033", 0, 164, 166, FebrabanLayout},
+		{"033", 1, 14, 16, FebrabanBatch},
+		{"033", 2, 38, 57, "1234567             "},
+	}
+
+	for _, tt := range tests {
+		lines := write(t, remittance(tt.bankCode))
+
+		if got := cnabtest.Field(lines[tt.line], tt.start, tt.end); got != tt.want {
+			t.Errorf("bank %s, line %d, positions %d-%d = %q, want %q", tt.bankCode, tt.line+1, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestValues_WriteErrors(t *testing.T) {
+	tests := []struct {
+		change func(r *cnab.Remittance)
+		err    error
+	}{
+		{func(r *cnab.Remittance) { r.BankCode = "75" }, ErrInvalidRemittance},
+		{func(r *cnab.Remittance) { r.Company.Name = "" }, ErrInvalidRemittance},
+		{func(r *cnab.Remittance) { r.Company.Document = "" }, ErrInvalidRemittance},
+		{func(r *cnab.Remittance) { r.Sequence = 0 }, ErrInvalidRemittance},
+		{func(r *cnab.Remittance) { r.Company.Account.Agency = "123456" }, cnab.ErrFieldOverflow},
+		{func(r *cnab.Remittance) { r.Titles[0].OurNumber = "" }, ErrInvalidTitle},
+		{func(r *cnab.Remittance) { r.Titles[0].Amount = 0 }, ErrInvalidTitle},
+		{func(r *cnab.Remittance) { r.Titles[0].DueDate = time.Time{} }, ErrInvalidTitle},
+		{func(r *cnab.Remittance) { r.Titles[1].Payer.Document = "" }, ErrInvalidTitle},
+		{func(r *cnab.Remittance) { r.Titles[1].Messages = make([]string, 8) }, ErrInvalidTitle},
+		{func(r *cnab.Remittance) { r.Titles[1].Pix.KeyType = 9 }, ErrInvalidTitle},
+		{func(r *cnab.Remittance) { r.Titles[0].OurNumber = "12345678901" }, cnab.ErrFieldOverflow},
+		{func(r *cnab.Remittance) { r.Titles[0].Payer.PostalCode = "013101000" }, cnab.ErrFieldOverflow},
+		// the batch total takes 17 digits
+		{func(r *cnab.Remittance) {
+			r.Titles[0].Amount = 999999999999999
+			r.Titles = slices.Repeat(r.Titles[:1], 101)
+		}, cnab.ErrFieldOverflow},
+	}
+
+	for i, tt := range tests {
+		r := remittance("756")
+		tt.change(r)
+
+		if err := Write(&bytes.Buffer{}, r); !errors.Is(err, tt.err) {
+			t.Errorf("case %d: Write returned error %v, want %v", i, err, tt.err)
+		}
+	}
+
+	Register(Bank{Code: "999", Name: "Banco de Teste"})
+
+	bank, ok := Lookup("999")
+	if !ok || bank.Remessa == nil || bank.Remessa.Name != "febraban-240-remessa" || bank.Retorno == nil {
+		t.Errorf("Lookup(999) = %+v, %v", bank, ok)
+	}
+
+	lines := write(t, remittance("999"))
+	if got := cnabtest.Field(lines[0], 103, 116); got != "BANCO DE TESTE" {
+		t.Errorf("bank name = %q", got)
+	}
+}
+
+func line(fields map[int]string) string {
+	return cnabtest.Line(RecordLength, fields)
+}
+
+func retorno(lines ...string) *Reader {
+	header := line(map[int]string{1: "2370000", 8: "0", 18: "212345678000195", 53: "043210", 59: "0000000987654", 73: "PADARIA PAO QUENTE LTDA", 143: "221112024", 158: "000007"})
+
+	return NewReader(strings.NewReader(strings.Join(append([]string{header}, lines...), "\r\n") + "\r\n"))
+}
+
+func segmentT(sequence, occurrence, ourNumber, amount string) string {
+	return line(map[int]string{1: "2370001", 8: "3", 9: sequence, 14: "T", 16: occurrence, 18: "0432100000000987654 ", 38: ourNumber, 59: "NF-1001", 74: "20112024", 82: amount, 97: "23701234", 106: "PEDIDO 1001", 133: "1000012345678909JOSE DA SILVA", 199: "000000000000250", 214: "A1B2"})
+}
+
+func segmentU(sequence string) string {
+	return line(map[int]string{1: "2370001", 8: "3", 9: sequence, 14: "U", 16: "06", 18: "000000000000150", 78: "000000000116187", 93: "000000000115937", 138: "2111202422112024"})
+}
+
+func TestValues_Read(t *testing.T) {
+	r := retorno(
+		line(map[int]string{1: "2370001", 8: "1"}),
+		segmentT("00001", "06", "01700000000001234567", "000000000116037"),
+		segmentU("00002"),
+		segmentT("00003", "02", "00900000000009876540", "000000000050000"),
+		line(map[int]string{1: "2370001", 8: "5"}),
+		line(map[int]string{1: "2379999", 8: "9"}),
+	)
+
+	var titles []*cnab.Return
+	for {
+		title, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Read returned %v", err)
+		}
+
+		titles = append(titles, title)
+	}
+
+	header := &cnab.Header{
+		BankCode: "237",
+		Company: cnab.Company{
+			Name:     "PADARIA PAO QUENTE LTDA",
+			Document: "12345678000195",
+			Account:  cnab.Account{Agency: "04321", AgencyDigit: "0", Number: "000000098765", Digit: "4"},
+		},
+		Sequence:  7,
+		CreatedAt: time.Date(2024, 11, 21, 0, 0, 0, 0, time.UTC),
+	}
+	if diff := cmp.Diff(header, r.Header()); diff != "" {
+		t.Errorf("Header mismatch (-want +got):\n%s", diff)
+	}
+
+	dueDate := time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC)
+	barcode, err := generator.Generate(generator.Request{BankCode: "237", Currency: generator.RealCurrency, DueDate: dueDate, Amount: 116037, FreeField: "4321170000012345600987650"})
+	if err != nil {
+		t.Fatalf("Generate returned %v", err)
+	}
+
+	account := cnab.Account{Agency: "04321", AgencyDigit: "0", Number: "000000098765", Digit: "4"}
+	payer := cnab.Payer{Name: "JOSE DA SILVA", Document: "12345678909"}
+
+	want := []*cnab.Return{
+		{
+			Line:             3,
+			Occurrence:       cnab.OccurrencePaid,
+			Reasons:          []string{"A1", "B2"},
+			BankCode:         "237",
+			Account:          cnab.Account{Agency: "04321", AgencyDigit: "0", Number: "000000098765", Digit: "4", Wallet: "17"},
+			OurNumber:        "00000123456",
+			OurNumberDigit:   "7",
+			DocumentNumber:   "NF-1001",
+			CompanyID:        "PEDIDO 1001",
+			DueDate:          dueDate,
+			Amount:           116037,
+			CollectingBank:   "237",
+			CollectingAgency: "01234",
+			Payer:            payer,
+			Tariff:           250,
+			Interest:         150,
+			PaidAmount:       116187,
+			CreditedAmount:   115937,
+			OccurrenceDate:   time.Date(2024, 11, 21, 0, 0, 0, 0, time.UTC),
+			CreditDate:       time.Date(2024, 11, 22, 0, 0, 0, 0, time.UTC),
+			Barcode:          barcode.Barcode,
+		},
+		{
+			Line:             5,
+			Occurrence:       cnab.OccurrenceRegistered,
+			Reasons:          []string{"A1", "B2"},
+			BankCode:         "237",
+			Account:          cnab.Account{Agency: account.Agency, AgencyDigit: "0", Number: account.Number, Digit: "4", Wallet: "09"},
+			OurNumber:        "00000987654",
+			OurNumberDigit:   "0",
+			DocumentNumber:   "NF-1001",
+			CompanyID:        "PEDIDO 1001",
+			DueDate:          dueDate,
+			Amount:           50000,
+			CollectingBank:   "237",
+			CollectingAgency: "01234",
+			Payer:            payer,
+			Tariff:           250,
+		},
+	}
+
+	want[1].Barcode = titles[1].Barcode
+	if diff := cmp.Diff(want, titles); diff != "" {
+		t.Errorf("Read mismatch (-want +got):\n%s", diff)
+	}
+
+	if titles[1].Barcode == "" || titles[1].Barcode[19:] != "4321090000098765400987650" {
+		t.Errorf("second barcode = %q", titles[1].Barcode)
+	}
+
+	if !titles[0].Occurrence.Paid() || titles[0].Occurrence.Description() == "" {
+		t.Errorf("occurrence %s is not described as paid", titles[0].Occurrence)
+	}
+}
+
+func TestValues_ReadErrors(t *testing.T) {
+	tests := []struct {
+		lines  []string
+		line   int
+		column int
+		err    error
+	}{
+		{[]string{"2370001"}, 2, 1, cnab.ErrInvalidLength},
+		{[]string{segmentU("00001")}, 2, 14, cnab.ErrUnexpectedRecord},
+		{[]string{segmentT("00001", "06", "01700000000001234567", "0000000001160X7")}, 2, 82, cnab.ErrInvalidValue},
+		{[]string{segmentT("00001", "06", "01700000000001234567", "000000000116037"), line(map[int]string{1: "2370001", 8: "3", 14: "U", 138: "31022024"})}, 3, 138, cnab.ErrInvalidValue},
+		{[]string{line(map[int]string{1: "2370001", 8: "7"})}, 2, 8, cnab.ErrUnexpectedRecord},
+	}
+
+	for i, tt := range tests {
+		_, err := retorno(tt.lines...).Read()
+
+		var lineErr *cnab.LineError
+		if !errors.As(err, &lineErr) || !errors.Is(err, tt.err) || lineErr.Line != tt.line || lineErr.Column != tt.column {
+			t.Errorf("case %d: Read returned error %v, want %v at line %d, column %d", i, err, tt.err, tt.line, tt.column)
+		}
+	}
+
+	r := NewReader(strings.NewReader(segmentT("00001", "06", "01700000000001234567", "000000000116037")))
+	if _, err := r.Read(); !errors.Is(err, cnab.ErrUnexpectedRecord) {
+		t.Errorf("Read without a file header returned %v", err)
+	}
+
+	r = retorno(line(map[int]string{1: "2370001", 8: "3", 14: "T", 74: "99999999"}), segmentU("00002"), segmentT("00003", "06", "01700000000001234567", "000000000116037"))
+	if _, err := r.Read(); !errors.Is(err, cnab.ErrInvalidValue) {
+		t.Errorf("Read returned %v, want the bad due date", err)
+	}
+
+	if title, err := r.Read(); err != nil || title.Line != 4 {
+		t.Errorf("Read after a bad segment T returned %+v, %v", title, err)
+	}
+
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Read at the end returned %v, want io.EOF", err)
+	}
+
+	r = retorno(segmentT("00001", "06", "01700000000001234567", "000000000116037"), line(map[int]string{1: "2370001", 8: "3", 9: "00002", 14: "U", 78: "00000000116X187"}), line(map[int]string{1: "2370001", 8: "5"}))
+	if _, err := r.Read(); !errors.Is(err, cnab.ErrInvalidValue) {
+		t.Errorf("Read returned %v, want the bad paid amount", err)
+	}
+
+	if title, err := r.Read(); err != nil || title.Line != 2 || title.Amount != 116037 || title.PaidAmount != 0 {
+		t.Errorf("Read after a bad segment U returned %+v, %v, want the title of segment T", title, err)
+	}
+
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Read at the end returned %v, want io.EOF", err)
+	}
+}
+
+func TestValues_ReadBanks(t *testing.T) {
+	tests := []struct {
+		bankCode  string
+		ourNumber string
+		want      cnab.Return
+	}{
+		{"033", "1234567             ", cnab.Return{OurNumber: "1234567"}},
+		{"104", "00014000000000123456", cnab.Return{OurNumber: "000000000123456"}},
+		{"237", "01700000000001234567", cnab.Return{OurNumber: "00000123456", OurNumberDigit: "7", Account: cnab.Account{Wallet: "17"}}},
+		{"756", "000123456701014     ", cnab.Return{OurNumber: "000123456", OurNumberDigit: "7"}},
+	}
+
+	for _, tt := range tests {
+		header := line(map[int]string{1: tt.bankCode + "0000", 8: "0", 143: "2"})
+		detail := line(map[int]string{1: tt.bankCode + "0001", 8: "3", 14: "T", 38: tt.ourNumber, 58: "1"})
+
+		title, err := NewReader(strings.NewReader(header + "\n" + detail)).Read()
+		if err != nil {
+			t.Fatalf("bank %s: Read returned %v", tt.bankCode, err)
+		}
+
+		got := cnab.Return{OurNumber: title.OurNumber, OurNumberDigit: title.OurNumberDigit, Account: cnab.Account{Wallet: title.Account.Wallet}}
+		if tt.want.Account.Wallet == "" {
+			tt.want.Account.Wallet = "1"
+		}
+
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("bank %s: Read mismatch (-want +got):\n%s", tt.bankCode, diff)
+		}
+	}
+}
+
+func TestValues_Layouts(t *testing.T) {
+	remessa, _ := layout.Lookup("febraban-240-remessa")
+
+	records := make([]*layout.Record, len(remessa.Records))
+	for i, record := range remessa.Records {
+		fields := slices.Clone(record.Fields)
+		if record.Name == "file header" {
+			i := slices.IndexFunc(fields, func(f layout.Field) bool { return f.Name == "layout version" })
+			fields[i].Default = "001"
+		}
+
+		records[i] = &layout.Record{Name: record.Name, Fields: fields}
+	}
+
+	Register(Bank{Code: "998", Remessa: &layout.Layout{Name: "teste-240-remessa", Length: RecordLength, Records: records}})
+
+	lines := write(t, remittance("998"))
+	if got := cnabtest.Field(lines[0], 164, 166); got != "001" {
+		t.Errorf("file layout version = %q, want 001", got)
+	}
+
+	Register(Bank{Code: "997", Remessa: &layout.Layout{Name: "teste-400-remessa", Length: 400, Records: records}})
+
+	if err := Write(&bytes.Buffer{}, remittance("997")); !errors.Is(err, layout.ErrInvalidLayout) {
+		t.Errorf("Write with a 400-position layout returned %v, want ErrInvalidLayout", err)
+	}
+}
@@ -0,0 +1,323 @@
+package cnab240
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/cnab/layout"
+	"github.com/fonini/go-boleto-utils/utils"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader reads the titles of a CNAB 240 retorno one at a time, joining the segments T and U
+// of each title. Segments other than T and U are skipped, and a segment T without its U, or
+// whose U is malformed, is returned on its own.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+	header  *cnab.Header
+	bank    Bank
+	retorno *layout.Layout
+	pending *cnab.Return
+	broken  bool
+	held    bool
+	done    bool
+}
+
+// NewReader returns a reader of the retorno in r
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024), 64*1024)
+
+	return &Reader{scanner: scanner}
+}
+
+// Header returns the file header, once Read has read it
+func (r *Reader) Header() *cnab.Header {
+	return r.header
+}
+
+// Read returns the next title of the file, and io.EOF after the last. Malformed lines return
+// a *cnab.LineError; reading can go on after one, from the next line.
+func (r *Reader) Read() (*cnab.Return, error) {
+	for {
+		if r.done {
+			return r.flush(io.EOF)
+		}
+
+		if r.held {
+			r.held = false
+		} else if r.scanner.Scan() {
+			r.line++
+		} else {
+			if err := r.scanner.Err(); err != nil {
+				return nil, err
+			}
+
+			r.done = true
+			continue
+		}
+
+		data := strings.TrimRight(r.scanner.Text(), "\r")
+
+		if data == "" {
+			continue
+		}
+
+		if len(data) != RecordLength {
+			r.pending, r.broken = nil, true
+			return nil, &cnab.LineError{Line: r.line, Column: 1, Err: fmt.Errorf("%w: %d positions, want %d", cnab.ErrInvalidLength, len(data), RecordLength)}
+		}
+
+		kind := data[7]
+
+		if r.header == nil && kind != FileHeader {
+			return nil, &cnab.LineError{Line: r.line, Column: 8, Field: "record type", Err: fmt.Errorf("%w: the file must start with a file header", cnab.ErrUnexpectedRecord)}
+		}
+
+		switch kind {
+		case FileHeader:
+			if r.header != nil {
+				return nil, &cnab.LineError{Line: r.line, Column: 8, Field: "record type", Err: fmt.Errorf("%w: second file header", cnab.ErrUnexpectedRecord)}
+			}
+
+			if err := r.readHeader(data); err != nil {
+				return nil, err
+			}
+		case BatchHeader:
+			if title, ok := r.take(); ok {
+				return title, nil
+			}
+		case Detail:
+			switch segment := data[13]; segment {
+			case 'T':
+				if title, ok := r.take(); ok {
+					r.held = true
+					return title, nil
+				}
+
+				title, err := r.readT(data)
+				r.pending, r.broken = title, err != nil
+
+				if err != nil {
+					return nil, err
+				}
+			case 'U':
+				if r.pending == nil {
+					if r.broken {
+						r.broken = false
+						continue
+					}
+
+					return nil, &cnab.LineError{Line: r.line, Column: 14, Field: "segment", Err: fmt.Errorf("%w: segment U without segment T", cnab.ErrUnexpectedRecord)}
+				}
+
+				// a malformed segment U leaves the title of segment T pending, to be
+				// returned on its own
+				title := *r.pending
+				if err := r.readU(data, &title); err != nil {
+					return nil, err
+				}
+
+				r.pending = &title
+				r.take()
+
+				return &title, nil
+			}
+		case BatchTrailer, FileTrailer:
+			if title, ok := r.take(); ok {
+				return title, nil
+			}
+		default:
+			return nil, &cnab.LineError{Line: r.line, Column: 8, Field: "record type", Err: fmt.Errorf("%w: type %q", cnab.ErrUnexpectedRecord, kind)}
+		}
+	}
+}
+
+// flush returns the title still waiting for its segment U, or err when there is none
+func (r *Reader) flush(err error) (*cnab.Return, error) {
+	if title, ok := r.take(); ok {
+		return title, nil
+	}
+
+	return nil, err
+}
+
+func (r *Reader) take() (*cnab.Return, bool) {
+	title := r.pending
+	r.pending = nil
+
+	if title != nil {
+		cnab.Link(title, r.bank.FreeField)
+	}
+
+	return title, title != nil
+}
+
+func (r *Reader) readHeader(data string) error {
+	bank, _ := Lookup(data[:3])
+
+	retorno, err := checked(bank.Retorno)
+	if err != nil {
+		return err
+	}
+
+	values, err := r.read(retorno, "file header", data)
+	if err != nil {
+		return err
+	}
+
+	if code := text(values, "file code"); code != "2" {
+		return &cnab.LineError{Line: r.line, Column: 143, Field: "file code", Err: fmt.Errorf("%w: %q is not a retorno", cnab.ErrInvalidValue, code)}
+	}
+
+	r.header = &cnab.Header{
+		BankCode: text(values, "bank code"),
+		Company: cnab.Company{
+			Document: strings.TrimLeft(text(values, "company document"), "0"),
+			Name:     text(values, "company name"),
+			Account:  account(values),
+		},
+		CreatedAt: date(values, "creation date"),
+	}
+
+	if sequence, err := strconv.Atoi(text(values, "sequence")); err == nil {
+		r.header.Sequence = sequence
+	}
+
+	r.bank, r.retorno = bank, retorno
+
+	return nil
+}
+
+func (r *Reader) readT(data string) (*cnab.Return, error) {
+	values, err := r.read(r.retorno, "segment T", data)
+	if err != nil {
+		return nil, err
+	}
+
+	title := &cnab.Return{
+		Line:             r.line,
+		BankCode:         text(values, "bank code"),
+		Occurrence:       cnab.Occurrence(text(values, "occurrence")),
+		Account:          account(values),
+		OurNumber:        text(values, "our number"),
+		OurNumberDigit:   text(values, "our number digit"),
+		DocumentNumber:   text(values, "document number"),
+		DueDate:          date(values, "due date"),
+		Amount:           money(values, "amount"),
+		CollectingBank:   text(values, "collecting bank"),
+		CollectingAgency: text(values, "collecting agency"),
+		CompanyID:        text(values, "company ID"),
+		Payer: cnab.Payer{
+			Document: trimDocument(text(values, "payer document"), text(values, "payer document type")),
+			Name:     text(values, "payer name"),
+		},
+		Tariff: money(values, "tariff"),
+	}
+
+	if ourNumber := text(values, "our number with digit"); ourNumber != "" {
+		title.OurNumber = ourNumber
+	}
+
+	if title.Account.Wallet == "" {
+		title.Account.Wallet = text(values, "wallet code")
+	}
+
+	reasons := text(values, "reasons")
+	for i := 0; i+2 <= len(reasons); i += 2 {
+		if reason := strings.TrimSpace(reasons[i : i+2]); reason != "" && reason != "00" {
+			title.Reasons = append(title.Reasons, reason)
+		}
+	}
+
+	return title, nil
+}
+
+func (r *Reader) readU(data string, title *cnab.Return) error {
+	values, err := r.read(r.retorno, "segment U", data)
+	if err != nil {
+		return err
+	}
+
+	title.Interest = money(values, "interest")
+	title.Discount = money(values, "discount")
+	title.Rebate = money(values, "rebate")
+	title.IOF = money(values, "IOF")
+	title.PaidAmount = money(values, "paid amount")
+	title.CreditedAmount = money(values, "credited amount")
+	title.OtherExpenses = money(values, "other expenses")
+	title.OtherCredits = money(values, "other credits")
+	title.OccurrenceDate = date(values, "occurrence date")
+	title.CreditDate = date(values, "credit date")
+
+	return nil
+}
+
+// read reads the record of l called name from data, and returns the field that can't be read
+// as a *cnab.LineError
+func (r *Reader) read(l *layout.Layout, name string, data string) (layout.Values, error) {
+	record, ok := l.Record(name)
+	if !ok {
+		return nil, &cnab.LineError{Line: r.line, Column: 1, Err: fmt.Errorf("%w: %s has no record %q", layout.ErrUnknownRecord, l.Name, name)}
+	}
+
+	values, err := record.Read(data)
+
+	var fieldErr *cnab.FieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		return nil, &cnab.LineError{Line: r.line, Column: fieldErr.Start, Field: fieldErr.Field, Err: fmt.Errorf("%w: %q", fieldErr.Err, fieldErr.Value)}
+	case err != nil:
+		return nil, &cnab.LineError{Line: r.line, Column: 1, Err: err}
+	}
+
+	return values, nil
+}
+
+// account reads the agency and account fields of a header or segment T
+func account(values layout.Values) cnab.Account {
+	return cnab.Account{
+		Agreement:   text(values, "agreement"),
+		Agency:      text(values, "agency"),
+		AgencyDigit: text(values, "agency digit"),
+		Number:      text(values, "account"),
+		Digit:       text(values, "account digit"),
+		Wallet:      text(values, "wallet"),
+	}
+}
+
+// trimDocument removes the zeros padding a CPF (type 1) to 15 digits and a CNPJ to 14
+func trimDocument(document string, personType string) string {
+	switch personType {
+	case "1":
+		if len(document) > 11 {
+			return document[len(document)-11:]
+		}
+	case "2":
+		if len(document) > 14 {
+			return document[len(document)-14:]
+		}
+	}
+
+	return document
+}
+
+func text(values layout.Values, name string) string {
+	value, _ := values[name].(string)
+	return value
+}
+
+func money(values layout.Values, name string) utils.Money {
+	value, _ := values[name].(utils.Money)
+	return value
+}
+
+func date(values layout.Values, name string) time.Time {
+	value, _ := values[name].(time.Time)
+	return value
+}
@@ -0,0 +1,391 @@
+package cnab240
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/banks"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/cnab/layout"
+	"github.com/fonini/go-boleto-utils/utils"
+	"io"
+	"time"
+)
+
+// maxMessages is the number of messages per title: two in segment R and five in segment S
+const maxMessages = 7
+
+// Write writes remittance as a CNAB 240 remessa in the variant of its bank: a file header,
+// one batch of cobrança with segments P and Q for every title, R, S and Y when the title
+// needs them, and the batch and file trailers with their counts and totals. Records end
+// with CRLF.
+func Write(w io.Writer, remittance *cnab.Remittance) error {
+	bank, _ := Lookup(remittance.BankCode)
+
+	lines, err := records(bank, remittance)
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := buffered.WriteString(line + "\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return buffered.Flush()
+}
+
+func records(bank Bank, remittance *cnab.Remittance) ([]string, error) {
+	if err := checkRemittance(remittance); err != nil {
+		return nil, err
+	}
+
+	remessa, err := checked(bank.Remessa)
+	if err != nil {
+		return nil, err
+	}
+
+	if bank.Name == "" {
+		if registered, err := banks.ByCode(bank.Code); err == nil {
+			bank.Name = registered.ShortName
+		}
+	}
+
+	createdAt := remittance.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	values := companyValues(bank, remittance)
+	values["bank name"] = bank.Name
+	values["creation date"] = createdAt
+	values["creation time"] = createdAt
+	values["sequence"] = remittance.Sequence
+
+	header, err := record(remessa, "file header", values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: file header: %w", ErrInvalidRemittance, err)
+	}
+
+	values = companyValues(bank, remittance)
+	values["batch"] = 1
+	values["remittance number"] = remittance.Sequence
+	values["recording date"] = createdAt
+
+	batch, err := record(remessa, "batch header", values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: batch header: %w", ErrInvalidRemittance, err)
+	}
+
+	lines := []string{header, batch}
+
+	var total utils.Money
+	for i, title := range remittance.Titles {
+		segments, err := titleSegments(bank, remessa, remittance, title, createdAt, len(lines)-2)
+		if err != nil {
+			return nil, fmt.Errorf("%w: title %d: %w", ErrInvalidTitle, i+1, err)
+		}
+
+		lines = append(lines, segments...)
+		total += title.Amount
+	}
+
+	// the batch counts its header and trailer
+	trailer, err := record(remessa, "batch trailer", layout.Values{
+		"bank code":     bank.Code,
+		"batch":         1,
+		"records":       len(lines),
+		"simple titles": len(remittance.Titles),
+		"simple total":  total,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: batch trailer: %w", ErrInvalidRemittance, err)
+	}
+
+	lines = append(lines, trailer)
+
+	trailer, err = record(remessa, "file trailer", layout.Values{
+		"bank code": bank.Code,
+		"batches":   1,
+		"records":   len(lines) + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: file trailer: %w", ErrInvalidRemittance, err)
+	}
+
+	return append(lines, trailer), nil
+}
+
+func checkRemittance(remittance *cnab.Remittance) error {
+	switch {
+	case len(remittance.BankCode) != 3 || utils.OnlyNumbers(remittance.BankCode) != remittance.BankCode:
+		return fmt.Errorf("%w: bank code %q", ErrInvalidRemittance, remittance.BankCode)
+	case remittance.Company.Name == "":
+		return fmt.Errorf("%w: missing company name", ErrInvalidRemittance)
+	case cnab.PersonType(remittance.Company.Document) == 0:
+		return fmt.Errorf("%w: missing company document", ErrInvalidRemittance)
+	case remittance.Sequence <= 0:
+		return fmt.Errorf("%w: file sequence must be positive", ErrInvalidRemittance)
+	}
+
+	return nil
+}
+
+// companyValues returns the values of the company and its account, which the headers and
+// segment P hold
+func companyValues(bank Bank, remittance *cnab.Remittance) layout.Values {
+	company := remittance.Company
+
+	return layout.Values{
+		"bank code":             bank.Code,
+		"company document type": cnab.PersonType(company.Document),
+		"company document":      utils.OnlyNumbers(company.Document),
+		"company name":          company.Name,
+		"agreement":             company.Account.Agreement,
+		"agency":                company.Account.Agency,
+		"agency digit":          company.Account.AgencyDigit,
+		"account":               company.Account.Number,
+		"account digit":         company.Account.Digit,
+		"wallet":                company.Account.Wallet,
+		"variation":             company.Account.Variation,
+	}
+}
+
+// titleSegments returns the detail records of title, numbered after the sequence last
+func titleSegments(bank Bank, remessa *layout.Layout, remittance *cnab.Remittance, title cnab.Title, createdAt time.Time, last int) ([]string, error) {
+	if err := checkTitle(title); err != nil {
+		return nil, err
+	}
+
+	// Alpha fields are cut to fit, but the nosso número must not be
+	if ourNumber := title.OurNumber + title.OurNumberDigit; len(ourNumber) > 20 {
+		return nil, &cnab.FieldError{Field: "our number", Start: 38, End: 57, Value: ourNumber, Err: cnab.ErrFieldOverflow}
+	}
+
+	var lines []string
+
+	segment := func(name string, values layout.Values) error {
+		values["bank code"] = bank.Code
+		values["batch"] = 1
+		values["sequence"] = last + len(lines) + 1
+		values["movement"] = string(movement(title))
+
+		line, err := record(remessa, "segment "+name, values)
+		if err != nil {
+			return err
+		}
+
+		lines = append(lines, line)
+
+		return nil
+	}
+
+	issueDate := title.IssueDate
+	if issueDate.IsZero() {
+		issueDate = createdAt
+	}
+
+	discount := discountAt(title, 0)
+
+	p := companyValues(bank, remittance)
+	p["our number"] = title.OurNumber
+	p["our number digit"] = title.OurNumberDigit
+	p["our number with digit"] = title.OurNumber + title.OurNumberDigit
+	p["document number"] = title.DocumentNumber
+	p["due date"] = title.DueDate
+	p["amount"] = title.Amount
+	p["kind"] = string(kind(title))
+	p["acceptance"] = acceptance(title)
+	p["issue date"] = issueDate
+	p["interest code"] = interestCode(title.Interest)
+	p["interest date"] = title.Interest.Date
+	p["interest"] = charge(title.Interest)
+	p["discount code"] = discount.Code
+	p["discount date"] = discount.Date
+	p["discount"] = charge(discount)
+	p["IOF"] = title.IOF
+	p["rebate"] = title.Rebate
+	p["company ID"] = title.CompanyID
+	p["protest code"] = 3
+	p["write-off code"] = 2
+
+	if title.ProtestDays > 0 {
+		p["protest code"] = 1
+		p["protest days"] = title.ProtestDays
+	}
+
+	if title.WriteOffDays > 0 {
+		p["write-off code"] = 1
+		p["write-off days"] = title.WriteOffDays
+	}
+
+	if err := segment("P", p); err != nil {
+		return nil, err
+	}
+
+	q := layout.Values{
+		"payer address":     title.Payer.Address,
+		"payer district":    title.Payer.District,
+		"payer postal code": utils.OnlyNumbers(title.Payer.PostalCode),
+		"payer city":        title.Payer.City,
+		"payer state":       title.Payer.State,
+	}
+
+	person(q, "payer", title.Payer)
+	if title.Guarantor != nil {
+		person(q, "guarantor", *title.Guarantor)
+	}
+
+	if err := segment("Q", q); err != nil {
+		return nil, err
+	}
+
+	if title.Fine.Code != cnab.ChargeNone || len(title.Discounts) > 1 || len(title.Messages) > 0 {
+		r := layout.Values{
+			"fine code": title.Fine.Code,
+			"fine date": title.Fine.Date,
+			"fine":      charge(title.Fine),
+			"message 3": messageAt(title, 0),
+			"message 4": messageAt(title, 1),
+		}
+
+		for i := 1; i <= 2; i++ {
+			discount := discountAt(title, i)
+			name := fmt.Sprintf("discount %d", i+1)
+
+			r[name+" code"] = discount.Code
+			r[name+" date"] = discount.Date
+			r[name] = charge(discount)
+		}
+
+		if err := segment("R", r); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(title.Messages) > 2 {
+		s := layout.Values{}
+		for i := 0; i < 5; i++ {
+			s[fmt.Sprintf("message %d", i+5)] = messageAt(title, i+2)
+		}
+
+		if err := segment("S", s); err != nil {
+			return nil, err
+		}
+	}
+
+	if title.Pix != nil {
+		y := layout.Values{
+			"PIX key type": title.Pix.KeyType,
+			"PIX key":      title.Pix.Key,
+			"PIX txid":     title.Pix.TxID,
+		}
+
+		if err := segment("Y", y); err != nil {
+			return nil, err
+		}
+	}
+
+	return lines, nil
+}
+
+func checkTitle(title cnab.Title) error {
+	switch {
+	case title.OurNumber == "":
+		return fmt.Errorf("missing nosso número")
+	case title.DueDate.IsZero():
+		return fmt.Errorf("missing due date")
+	case title.Amount <= 0:
+		return fmt.Errorf("amount must be positive")
+	case title.Payer.Name == "" || cnab.PersonType(title.Payer.Document) == 0:
+		return fmt.Errorf("missing payer name or document")
+	case len(title.Discounts) > 3:
+		return fmt.Errorf("at most 3 discounts, got %d", len(title.Discounts))
+	case len(title.Messages) > maxMessages:
+		return fmt.Errorf("at most %d messages, got %d", maxMessages, len(title.Messages))
+	case title.Pix != nil && (title.Pix.KeyType < cnab.PixKeyCPF || title.Pix.KeyType > cnab.PixKeyRandom):
+		return fmt.Errorf("invalid PIX key type %d", title.Pix.KeyType)
+	}
+
+	return nil
+}
+
+// person sets the document type, document and name of a payer or guarantor
+func person(values layout.Values, field string, payer cnab.Payer) {
+	values[field+" document type"] = cnab.PersonType(payer.Document)
+	values[field+" document"] = utils.OnlyNumbers(payer.Document)
+	values[field+" name"] = payer.Name
+}
+
+// charge returns the amount, or the rate with two decimal places, of a 15-position field
+func charge(c cnab.Charge) any {
+	if c.Code == cnab.ChargePercent {
+		return c.Rate
+	}
+
+	return c.Value
+}
+
+// interestCode maps the charge codes to the interest codes of segment P: 1 for an amount
+// per day, 2 for a monthly rate and 3 for no interest
+func interestCode(interest cnab.Charge) int {
+	switch interest.Code {
+	case cnab.ChargeAmount:
+		return 1
+	case cnab.ChargePercent:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func movement(title cnab.Title) cnab.Movement {
+	if title.Movement == "" {
+		return cnab.MovementRegister
+	}
+
+	return title.Movement
+}
+
+func kind(title cnab.Title) cnab.Kind {
+	if title.Kind == "" {
+		return cnab.KindDuplicataMercantil
+	}
+
+	return title.Kind
+}
+
+func acceptance(title cnab.Title) string {
+	if title.Accepted {
+		return "A"
+	}
+
+	return "N"
+}
+
+func discountAt(title cnab.Title, i int) cnab.Charge {
+	if i < len(title.Discounts) {
+		return title.Discounts[i]
+	}
+
+	return cnab.Charge{}
+}
+
+func messageAt(title cnab.Title, i int) string {
+	if i < len(title.Messages) {
+		return title.Messages[i]
+	}
+
+	return ""
+}
+
+// record writes the record of l called name holding values
+func record(l *layout.Layout, name string, values layout.Values) (string, error) {
+	r, ok := l.Record(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s has no record %q", layout.ErrUnknownRecord, l.Name, name)
+	}
+
+	return r.Write(values)
+}
@@ -0,0 +1,124 @@
+package cnab400
+
+import (
+	"github.com/fonini/go-boleto-utils/cnab"
+)
+
+// bradesco is the layout of Banco Bradesco (237), with the beneficiary identified by the
+// wallet, agency and account in the details
+var bradesco = bundled("bradesco", Bank{
+	Code: "237",
+	Kinds: map[cnab.Kind]string{
+		cnab.KindDuplicataMercantil: "01",
+		cnab.KindNotaPromissoria:    "02",
+		cnab.KindRecibo:             "05",
+		cnab.KindDuplicataServico:   "12",
+		cnab.KindOther:              "99",
+	},
+	DetailValues: func(values Values, remittance *cnab.Remittance, title cnab.Title) error {
+		account := remittance.Company.Account
+
+		beneficiary := cnab.NewRecord(17)
+		beneficiary.Digits("zero", 1, 1, "0")
+		beneficiary.Digits("wallet", 2, 4, account.Wallet)
+		beneficiary.Digits("agency", 5, 9, account.Agency)
+		beneficiary.Digits("account", 10, 16, account.Number)
+		beneficiary.Digits("account digit", 17, 17, account.Digit)
+
+		if err := beneficiary.Err(); err != nil {
+			return err
+		}
+
+		values["beneficiary"] = beneficiary.String()
+
+		if title.Fine.Code == cnab.ChargePercent {
+			values["fine code"] = 2
+		}
+
+		if title.ProtestDays > 0 {
+			values["instruction 1"] = "06"
+			values["instruction 2"] = title.ProtestDays
+		}
+
+		return nil
+	},
+	ParseReturn: func(values Values, r *cnab.Return) {
+		if beneficiary := text(values, "beneficiary"); len(beneficiary) == 17 {
+			r.Account.Wallet = beneficiary[2:4]
+			r.Account.Agency = beneficiary[4:9]
+			r.Account.Number = beneficiary[9:16]
+			r.Account.Digit = beneficiary[16:17]
+		}
+	},
+	FreeField: cnab.BradescoFreeField,
+})
+
+// itau is the layout of Itaú Unibanco (341), with an 8-digit nosso número and the protest
+// days after the payer's address
+var itau = bundled("itau", Bank{
+	Code: "341",
+	Kinds: map[cnab.Kind]string{
+		cnab.KindDuplicataMercantil: "01",
+		cnab.KindNotaPromissoria:    "02",
+		cnab.KindRecibo:             "05",
+		cnab.KindDuplicataServico:   "08",
+		cnab.KindOther:              "99",
+	},
+	DetailValues: func(values Values, _ *cnab.Remittance, title cnab.Title) error {
+		if title.ProtestDays > 0 {
+			values["instruction 1"] = "09"
+		}
+
+		return nil
+	},
+	FreeField: func(r *cnab.Return) (string, bool) {
+		if r.OurNumberDigit == "" || r.Account.Digit == "" {
+			return "", false
+		}
+
+		freeField := cnab.NewRecord(25)
+		freeField.Digits("wallet", 1, 3, r.Account.Wallet)
+		freeField.Digits("our number", 4, 11, r.OurNumber)
+		freeField.Digits("our number digit", 12, 12, r.OurNumberDigit)
+		freeField.Digits("agency", 13, 16, r.Account.Agency)
+		freeField.Digits("account", 17, 21, r.Account.Number)
+		freeField.Digits("account digit", 22, 22, r.Account.Digit)
+		freeField.Digits("zeros", 23, 25, "0")
+
+		return freeField.String(), freeField.Err() == nil
+	},
+})
+
+// sicredi is the layout of Banco Cooperativo Sicredi (748), with letter codes, AAAAMMDD
+// dates in the header and a 9-digit nosso número that includes its digit
+var sicredi = bundled("sicredi", Bank{
+	Code: "748",
+	Kinds: map[cnab.Kind]string{
+		cnab.KindDuplicataMercantil: "A",
+		cnab.KindNotaPromissoria:    "C",
+		cnab.KindRecibo:             "G",
+		cnab.KindDuplicataServico:   "J",
+		cnab.KindOther:              "K",
+	},
+	DetailValues: func(values Values, _ *cnab.Remittance, title cnab.Title) error {
+		values["our number"] = title.OurNumber + title.OurNumberDigit
+
+		values["acceptance"] = "N"
+		if title.Accepted {
+			values["acceptance"] = "S"
+		}
+
+		if title.ProtestDays > 0 {
+			values["instruction 1"] = "06"
+			values["instruction 2"] = title.ProtestDays
+		}
+
+		return nil
+	},
+	ParseReturn: func(values Values, r *cnab.Return) {
+		if ourNumber := text(values, "our number"); len(ourNumber) == 9 {
+			r.OurNumber = ourNumber[:8]
+			r.OurNumberDigit = ourNumber[8:]
+		}
+	},
+})
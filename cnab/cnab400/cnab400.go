@@ -0,0 +1,147 @@
+// Package cnab400 writes and reads the CNAB 400 layout for cobrança: files of 400-position
+// records made of a header, one detail per title and a trailer. Unlike CNAB 240, CNAB 400
+// was never standardized, so every bank has its own field map. The maps of Bradesco, Itaú
+// and Sicredi are read from the bundled layouts of package layout, and FromLayout builds
+// others from layout files.
+package cnab400
+
+import (
+	"errors"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"sort"
+	"sync"
+)
+
+// RecordLength is the length of every record, not counting the line break
+const RecordLength = 400
+
+// Record types (tipo de registro), at position 1
+const (
+	Header  = '0'
+	Detail  = '1'
+	Trailer = '9'
+)
+
+var (
+	// ErrUnsupportedBank is returned for banks without a registered field map
+	ErrUnsupportedBank = errors.New("bank has no CNAB 400 layout")
+	// ErrInvalidRemittance is returned when a remessa lacks a field every file needs
+	ErrInvalidRemittance = errors.New("invalid CNAB 400 remittance")
+	// ErrInvalidTitle is returned when a title can't be registered
+	ErrInvalidTitle = errors.New("invalid CNAB 400 title")
+)
+
+// Format is how the value of a field is written and read
+type Format int
+
+const (
+	// Alpha fields are left-aligned and padded with blanks
+	Alpha Format = iota
+	// Digits fields are right-aligned and padded with zeros
+	Digits
+	// Money fields hold centavos
+	Money
+	// Rate fields hold a percentage with two decimal places
+	Rate
+	// Date fields are DDMMAA, or DDMMAAAA in 8 positions
+	Date
+	// ISODate fields are AAAAMMDD
+	ISODate
+)
+
+// Field is the position, 1-based and inclusive, and the format of a field. A field with a
+// Value always holds it: the constants of the layout.
+type Field struct {
+	Start  int
+	End    int
+	Format Format
+	Value  string
+}
+
+// Fields maps the names of the fields of a record to their positions. Fields the file has
+// no value for are written as zeros, or blanks for Alpha fields.
+//
+// The writer fills the names "company document type", "company document", "company name",
+// "company code" (the agreement), "agency", "account", "account digit", "wallet", "bank
+// code", "bank name", "creation date", "sequence" and "record sequence" in every record,
+// and these in details: "movement", "our number", "our number digit", "document number",
+// "company ID", "kind", "acceptance", "issue date", "due date", "amount", "interest" (per
+// day), "interest date", "discount", "discount date", "IOF", "rebate", "fine rate", "fine
+// date", "protest days", "message", "payer document type", "payer document", "payer name",
+// "payer address", "payer district", "payer postal code", "payer city", "payer state",
+// "guarantor document type", "guarantor document" and "guarantor name".
+//
+// The reader sets the fields of cnab.Header and cnab.Return of the same names, and
+// "occurrence", "occurrence date", "reasons", "collecting bank", "collecting agency",
+// "tariff", "paid amount", "credited amount", "other expenses", "other credits", "fine"
+// (added to Interest) and "credit date".
+type Fields map[string]Field
+
+// Values holds the values of the fields of a record by name: strings, ints, utils.Money,
+// float64 rates and time.Time dates
+type Values map[string]any
+
+// Bank is the CNAB 400 layout of a bank: the field maps of the remessa and retorno records
+// and the codes and values only the bank uses
+type Bank struct {
+	Code         string
+	Header       Fields
+	Detail       Fields
+	Trailer      Fields
+	ReturnHeader Fields
+	ReturnDetail Fields
+	// Kinds maps the kinds of title to the bank's espécie codes
+	Kinds map[cnab.Kind]string
+	// HeaderValues and DetailValues set the values of the bank's own fields
+	HeaderValues func(values Values, remittance *cnab.Remittance) error
+	DetailValues func(values Values, remittance *cnab.Remittance, title cnab.Title) error
+	// ParseReturn reads the bank's own fields of a retorno detail into r
+	ParseReturn func(values Values, r *cnab.Return)
+	// FreeField is passed to cnab.Link to set the barcode of the titles of a retorno
+	FreeField func(r *cnab.Return) (string, bool)
+}
+
+var (
+	mu       sync.RWMutex
+	variants = map[string]Bank{
+		bradesco.Code: bradesco,
+		itau.Code:     itau,
+		sicredi.Code:  sicredi,
+	}
+)
+
+// Register sets the layout used for the files of bank.Code, replacing any previous one
+func Register(bank Bank) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	variants[bank.Code] = bank
+}
+
+// Lookup returns the layout registered for bankCode
+func Lookup(bankCode string) (Bank, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	bank, ok := variants[bankCode]
+
+	return bank, ok
+}
+
+// names returns the names of fields in the order of their positions
+func (f Fields) names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if f[names[i]].Start != f[names[j]].Start {
+			return f[names[i]].Start < f[names[j]].Start
+		}
+
+		return names[i] < names[j]
+	})
+
+	return names
+}
@@ -0,0 +1,320 @@
+package cnab400
+
+import (
+	"bytes"
+	"errors"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/cnab/internal/cnabtest"
+	"github.com/fonini/go-boleto-utils/cnab/layout"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func remittance(bankCode string) *cnab.Remittance {
+	return cnabtest.Remittance(bankCode,
+		cnab.Account{Agency: "4321", Number: "98765", Digit: "4", Agreement: "12345", Wallet: "109"},
+		cnab.Title{
+			OurNumber:      "12345678",
+			OurNumberDigit: "9",
+			DocumentNumber: "NF-1001",
+			CompanyID:      "pedido 1001",
+			DueDate:        time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC),
+			Amount:         116037,
+			Kind:           cnab.KindDuplicataServico,
+			Accepted:       true,
+			Interest:       cnab.Charge{Code: cnab.ChargePercent, Rate: 3},
+			Fine:           cnab.Charge{Code: cnab.ChargePercent, Rate: 2},
+			Discounts:      []cnab.Charge{{Code: cnab.ChargeAmount, Date: time.Date(2024, 11, 15, 0, 0, 0, 0, time.UTC), Value: 1000}},
+			ProtestDays:    5,
+			Payer:          cnabtest.Payer(),
+			Guarantor:      &cnab.Payer{Name: "Banco Avalista", Document: "11.222.333/0001-81"},
+		},
+	)
+}
+
+func write(t *testing.T, r *cnab.Remittance) []string {
+	return cnabtest.Lines(t, Write, r, RecordLength)
+}
+
+func TestValues_Write(t *testing.T) {
+	tests := []struct {
+		bankCode   string
+		line       int
+		start, end int
+		want       string
+	}{
+		// Bradesco
+		{"237", 0, 1, 46, "01REMESSA01COBRANCA       00000000000000012345"},
+		{"237", 0, 77, 117, "237BRADESCO       101124        MX0000007"},
+		{"237", 0, 395, 400, "000001"},
+		{"237", 1, 1, 37, "1000000000000000000001090432100987654"},
+		{"237", 1, 38, 94, "PEDIDO 1001              0002020000012345678900000000002N"},
+		{"237", 1, 109, 160, "01NF-1001   20112400000001160370000000012N1011240605"},
+		{"237", 1, 161, 192, "00000000001161511240000000001000"},
+		{"237", 1, 219, 274, "0100012345678909JOSE DA SILVA                           "},
+		{"237", 1, 315, 334, "            01310100"},
+		{"237", 1, 335, 400, "BANCO AVALISTA                                              000002"},
+		{"237", 2, 1, 1, "9"},
+		{"237", 2, 395, 400, "000003"},
+		// Itaú
+		{"341", 0, 27, 38, "432100987654"},
+		{"341", 0, 77, 100, "341BANCO ITAU SA  101124"},
+		{"341", 1, 1, 37, "10212345678000195432100987654    0000"},
+		{"341", 1, 63, 86, "123456780000000000000109"},
+		{"341", 1, 108, 160, "I01NF-1001   20112400000001160373410000008A1011240900"},
+		{"341", 1, 235, 264, "JOSE DA SILVA                 "},
+		{"341", 1, 315, 351, "CENTRO      01310100SAO PAULO      SP"},
+		{"341", 1, 352, 400, "BANCO AVALISTA                    00000005 000002"},
+		// Sicredi
+		{"748", 0, 27, 45, "1234512345678000195"},
+		{"748", 0, 77, 117, "748SICREDI        20241110        0000007"},
+		{"748", 0, 391, 400, "2.00000001"},
+		{"748", 1, 1, 19, "1AAA            AAA"},
+		{"748", 1, 48, 74, "123456789      20241110 N B"},
+		{"748", 1, 83, 96, "00000000000200"},
+		{"748", 1, 149, 160, "JS1011240605"},
+		{"748", 1, 219, 234, "1000012345678909"},
+		{"748", 1, 340, 360, "11222333000181BANCO A"},
+		{"748", 2, 1, 10, "9174812345"},
+	}
+
+	for _, tt := range tests {
+		lines := write(t, remittance(tt.bankCode))
+
+		if got := cnabtest.Field(lines[tt.line], tt.start, tt.end); got != tt.want {
+			t.Errorf("bank %s, line %d, positions %d-%d = %q, want %q", tt.bankCode, tt.line+1, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestValues_WriteErrors(t *testing.T) {
+	tests := []struct {
+		change func(r *cnab.Remittance)
+		err    error
+	}{
+		{func(r *cnab.Remittance) { r.BankCode = "756" }, ErrUnsupportedBank},
+		{func(r *cnab.Remittance) { r.Company.Name = "" }, ErrInvalidRemittance},
+		{func(r *cnab.Remittance) { r.Sequence = 0 }, ErrInvalidRemittance},
+		{func(r *cnab.Remittance) { r.Titles[0].Amount = 0 }, ErrInvalidTitle},
+		{func(r *cnab.Remittance) { r.Titles[0].Fine.Code = cnab.ChargeAmount }, ErrInvalidTitle},
+		{func(r *cnab.Remittance) { r.Titles[0].OurNumber = "123456789012" }, cnab.ErrFieldOverflow},
+		{func(r *cnab.Remittance) { r.Company.Account.Wallet = "1A" }, cnab.ErrInvalidValue},
+	}
+
+	for i, tt := range tests {
+		r := remittance("237")
+		tt.change(r)
+
+		if err := Write(&bytes.Buffer{}, r); !errors.Is(err, tt.err) {
+			t.Errorf("case %d: Write returned error %v, want %v", i, err, tt.err)
+		}
+	}
+
+	custom := Bank{
+		Code:    "756",
+		Header:  Fields{"record type": {1, 1, Digits, "0"}, "company name": {2, 31, Alpha, ""}},
+		Detail:  Fields{"record type": {1, 1, Digits, "1"}, "amount": {2, 14, Money, ""}, "due date": {15, 22, Date, ""}},
+		Trailer: Fields{"record type": {1, 1, Digits, "9"}, "total": {2, 14, Money, ""}, "record sequence": {395, 400, Digits, ""}},
+	}
+	Register(custom)
+
+	lines := write(t, remittance("756"))
+	if got := cnabtest.Field(lines[1], 1, 22); got != "1000000011603720112024" {
+		t.Errorf("custom detail = %q", got)
+	}
+
+	if got := cnabtest.Field(lines[2], 1, 14) + cnabtest.Field(lines[2], 395, 400); got != "90000000116037000003" {
+		t.Errorf("custom trailer = %q", got)
+	}
+}
+
+func line(fields map[int]string) string {
+	return cnabtest.Line(RecordLength, fields)
+}
+
+func readAll(t *testing.T, lines ...string) (*Reader, []*cnab.Return) {
+	t.Helper()
+
+	r := NewReader(strings.NewReader(strings.Join(lines, "\r\n") + "\r\n"))
+
+	var titles []*cnab.Return
+	for {
+		title, err := r.Read()
+		if err == io.EOF {
+			return r, titles
+		}
+
+		if err != nil {
+			t.Fatalf("Read returned %v", err)
+		}
+
+		titles = append(titles, title)
+	}
+}
+
+func TestValues_ReadBradesco(t *testing.T) {
+	r, titles := readAll(t,
+		line(map[int]string{1: "02RETORNO01COBRANCA", 27: "00000000000000012345", 47: "PADARIA PAO QUENTE LTDA", 77: "237BRADESCO", 95: "211124", 109: "00042", 395: "000001"}),
+		line(map[int]string{1: "10212345678000195", 21: "00090432100987654", 38: "PEDIDO 1001", 71: "000001234567", 109: "06211124NF-1001", 147: "2011240000000116037237012340", 176: "0000000000250", 254: "00000001161870000000000150", 296: "221124", 319: "A1", 395: "000002"}),
+		line(map[int]string{1: "9", 395: "000003"}),
+	)
+
+	header := &cnab.Header{
+		BankCode: "237",
+		Company: cnab.Company{
+			Name:    "PADARIA PAO QUENTE LTDA",
+			Account: cnab.Account{Agreement: "00000000000000012345"},
+		},
+		Sequence:  42,
+		CreatedAt: time.Date(2024, 11, 21, 0, 0, 0, 0, time.UTC),
+	}
+	if diff := cmp.Diff(header, r.Header()); diff != "" {
+		t.Errorf("Header mismatch (-want +got):\n%s", diff)
+	}
+
+	want := []*cnab.Return{{
+		Line:             2,
+		Occurrence:       cnab.OccurrencePaid,
+		Reasons:          []string{"A1"},
+		BankCode:         "237",
+		Account:          cnab.Account{Agency: "04321", Number: "0098765", Digit: "4", Wallet: "09"},
+		OurNumber:        "00000123456",
+		OurNumberDigit:   "7",
+		DocumentNumber:   "NF-1001",
+		CompanyID:        "PEDIDO 1001",
+		DueDate:          time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC),
+		Amount:           116037,
+		CollectingBank:   "237",
+		CollectingAgency: "01234",
+		Tariff:           250,
+		Interest:         150,
+		PaidAmount:       116187,
+		OccurrenceDate:   time.Date(2024, 11, 21, 0, 0, 0, 0, time.UTC),
+		CreditDate:       time.Date(2024, 11, 22, 0, 0, 0, 0, time.UTC),
+		Barcode:          "23791990600001160374321090000012345600987650",
+	}}
+
+	if diff := cmp.Diff(want, titles); diff != "" {
+		t.Errorf("Read mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValues_ReadBanks(t *testing.T) {
+	tests := []struct {
+		bankCode string
+		header   map[int]string
+		detail   map[int]string
+		want     *cnab.Return
+	}{
+		{
+			"341",
+			map[int]string{1: "02RETORNO01COBRANCA", 27: "432100987654", 77: "341", 95: "211124"},
+			map[int]string{1: "1", 18: "432100987654", 83: "109123456786", 109: "06211124", 147: "2011240000000116037", 254: "0000000116037", 296: "221124", 378: "0304"},
+			&cnab.Return{
+				Line:           2,
+				Occurrence:     cnab.OccurrencePaid,
+				Reasons:        []string{"03", "04"},
+				BankCode:       "341",
+				Account:        cnab.Account{Agency: "4321", Number: "98765", Digit: "4", Wallet: "109"},
+				OurNumber:      "12345678",
+				OurNumberDigit: "6",
+				DueDate:        time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC),
+				Amount:         116037,
+				PaidAmount:     116037,
+				OccurrenceDate: time.Date(2024, 11, 21, 0, 0, 0, 0, time.UTC),
+				CreditDate:     time.Date(2024, 11, 22, 0, 0, 0, 0, time.UTC),
+				Barcode:        "34194990600001160371091234567864321987654000",
+			},
+		},
+		{
+			"748",
+			map[int]string{1: "02RETORNO01COBRANCA", 27: "12345", 77: "748", 95: "20241121"},
+			map[int]string{1: "1", 48: "241000019", 109: "06211124NF-1001", 147: "2011240000000116037", 254: "00000001163370000000000100", 280: "0000000000200", 329: "20241122"},
+			&cnab.Return{
+				Line:           2,
+				Occurrence:     cnab.OccurrencePaid,
+				BankCode:       "748",
+				OurNumber:      "24100001",
+				OurNumberDigit: "9",
+				DocumentNumber: "NF-1001",
+				DueDate:        time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC),
+				Amount:         116037,
+				PaidAmount:     116337,
+				Interest:       300,
+				OccurrenceDate: time.Date(2024, 11, 21, 0, 0, 0, 0, time.UTC),
+				CreditDate:     time.Date(2024, 11, 22, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		_, titles := readAll(t, line(tt.header), line(tt.detail), line(map[int]string{1: "9"}))
+
+		if diff := cmp.Diff([]*cnab.Return{tt.want}, titles); diff != "" {
+			t.Errorf("bank %s: Read mismatch (-want +got):\n%s", tt.bankCode, diff)
+		}
+	}
+}
+
+func TestValues_ReadErrors(t *testing.T) {
+	header := line(map[int]string{1: "02RETORNO01COBRANCA", 77: "237"})
+
+	tests := []struct {
+		lines  []string
+		line   int
+		column int
+		err    error
+	}{
+		{[]string{header, "1"}, 2, 1, cnab.ErrInvalidLength},
+		{[]string{line(map[int]string{1: "1"})}, 1, 1, cnab.ErrUnexpectedRecord},
+		{[]string{line(map[int]string{1: "01REMESSA", 77: "237"})}, 1, 2, cnab.ErrInvalidValue},
+		{[]string{line(map[int]string{1: "02RETORNO", 77: "999"})}, 1, 77, ErrUnsupportedBank},
+		{[]string{header, line(map[int]string{1: "1", 153: "00000001160X7"})}, 2, 153, cnab.ErrInvalidValue},
+		{[]string{header, line(map[int]string{1: "1", 147: "311124"})}, 2, 147, cnab.ErrInvalidValue},
+	}
+
+	for i, tt := range tests {
+		_, err := NewReader(strings.NewReader(strings.Join(tt.lines, "\n"))).Read()
+
+		var lineErr *cnab.LineError
+		if !errors.As(err, &lineErr) || !errors.Is(err, tt.err) || lineErr.Line != tt.line || lineErr.Column != tt.column {
+			t.Errorf("case %d: Read returned error %v, want %v at line %d, column %d", i, err, tt.err, tt.line, tt.column)
+		}
+	}
+}
+
+func TestValues_FromLayout(t *testing.T) {
+	remessa, _ := layout.Lookup("itau-400-remessa")
+	retorno, _ := layout.Lookup("itau-400-retorno")
+
+	bank, err := FromLayout("341", remessa, retorno)
+	if err != nil {
+		t.Fatalf("FromLayout returned %v", err)
+	}
+
+	if diff := cmp.Diff(itau.Detail, bank.Detail); diff != "" {
+		t.Errorf("Detail mismatch (-want +got):\n%s", diff)
+	}
+
+	febraban, _ := layout.Lookup("febraban-240-remessa")
+	if _, err := FromLayout("341", febraban, retorno); !errors.Is(err, layout.ErrInvalidLayout) {
+		t.Errorf("FromLayout with a CNAB 240 layout returned %v", err)
+	}
+
+	custom, err := layout.Load(strings.NewReader(`{"name": "custom", "length": 400, "records": [
+		{"name": "header", "fields": [{"name": "record type", "start": 1, "length": 1, "type": "numeric", "default": "0", "key": true}]},
+		{"name": "detail", "fields": [{"name": "record type", "start": 1, "length": 1, "type": "numeric", "default": "1", "key": true}, {"name": "rate", "start": 2, "length": 6, "type": "decimal", "decimals": 4}]},
+		{"name": "trailer", "fields": [{"name": "record type", "start": 1, "length": 1, "type": "numeric", "default": "9", "key": true}]}
+	]}`))
+	if err != nil {
+		t.Fatalf("Load returned %v", err)
+	}
+
+	if _, err := FromLayout("999", custom, retorno); !errors.Is(err, layout.ErrInvalidLayout) {
+		t.Errorf("FromLayout with a 4-decimal rate returned %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package cnab400
+
+import (
+	"fmt"
+	"github.com/fonini/go-boleto-utils/cnab/layout"
+)
+
+// FromLayout returns the layout of bankCode read from the declarative layouts of its remessa
+// and retorno, whose records must be called header, detail and trailer. Defaults become
+// the constants of the fields. Set the bank's Kinds and hooks on the result, if it needs
+// any, before registering it.
+func FromLayout(bankCode string, remessa, retorno *layout.Layout) (Bank, error) {
+	bank := Bank{Code: bankCode}
+
+	records := []struct {
+		l      *layout.Layout
+		name   string
+		fields *Fields
+	}{
+		{remessa, "header", &bank.Header},
+		{remessa, "detail", &bank.Detail},
+		{remessa, "trailer", &bank.Trailer},
+		{retorno, "header", &bank.ReturnHeader},
+		{retorno, "detail", &bank.ReturnDetail},
+	}
+
+	for _, r := range records {
+		if r.l.Length != RecordLength {
+			return Bank{}, fmt.Errorf("%w: %s has %d-position records", layout.ErrInvalidLayout, r.l.Name, r.l.Length)
+		}
+
+		record, ok := r.l.Record(r.name)
+		if !ok {
+			return Bank{}, fmt.Errorf("%w: %s has no record %q", layout.ErrUnknownRecord, r.l.Name, r.name)
+		}
+
+		fields, err := fromRecord(record)
+		if err != nil {
+			return Bank{}, fmt.Errorf("%w: %s: %w", layout.ErrInvalidLayout, r.l.Name, err)
+		}
+
+		*r.fields = fields
+	}
+
+	return bank, nil
+}
+
+func fromRecord(record *layout.Record) (Fields, error) {
+	fields := Fields{}
+
+	for _, f := range record.Fields {
+		field := Field{Start: f.Start, End: f.End(), Value: f.Default}
+
+		switch {
+		case f.Padding != "":
+			return nil, fmt.Errorf("field %s: CNAB 400 fields take the default padding", f.Name)
+		case f.Type == layout.Alpha:
+			field.Format = Alpha
+		case f.Type == layout.Numeric:
+			field.Format = Digits
+		case f.Type == layout.Money && (f.Decimals == 0 || f.Decimals == 2):
+			field.Format = Money
+		case f.Type == layout.Decimal && f.Decimals == 2:
+			field.Format = Rate
+		case f.Type == layout.Date && (f.Format == "" || f.Format == "DDMMAA" || f.Format == "DDMMAAAA"):
+			field.Format = Date
+		case f.Type == layout.Date && f.Format == "AAAAMMDD":
+			field.Format = ISODate
+		default:
+			return nil, fmt.Errorf("field %s: CNAB 400 has no %s fields in format %q with %d decimal places", f.Name, f.Type, f.Format, f.Decimals)
+		}
+
+		fields[f.Name] = field
+	}
+
+	return fields, nil
+}
+
+// bundled returns bank with the field maps of the bundled layouts named after name
+func bundled(name string, bank Bank) Bank {
+	remessa, _ := layout.Lookup(name + "-400-remessa")
+	retorno, _ := layout.Lookup(name + "-400-retorno")
+
+	if remessa == nil || retorno == nil {
+		panic("cnab400: missing bundled layout for " + name)
+	}
+
+	fields, err := FromLayout(bank.Code, remessa, retorno)
+	if err != nil {
+		panic(err)
+	}
+
+	bank.Header, bank.Detail, bank.Trailer = fields.Header, fields.Detail, fields.Trailer
+	bank.ReturnHeader, bank.ReturnDetail = fields.ReturnHeader, fields.ReturnDetail
+
+	return bank
+}
@@ -0,0 +1,236 @@
+package cnab400
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/utils"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader reads the titles of a CNAB 400 retorno one at a time, in the layout of the bank
+// named by the header. Records other than the header, details and trailer are skipped.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+	header  *cnab.Header
+	bank    Bank
+}
+
+// NewReader returns a reader of the retorno in r
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024), 64*1024)
+
+	return &Reader{scanner: scanner}
+}
+
+// Header returns the file header, once Read has read it
+func (r *Reader) Header() *cnab.Header {
+	return r.header
+}
+
+// Read returns the next title of the file, and io.EOF after the last. Malformed lines return
+// a *cnab.LineError; reading can go on after one, from the next line.
+func (r *Reader) Read() (*cnab.Return, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := strings.TrimRight(r.scanner.Text(), "\r")
+
+		if data == "" {
+			continue
+		}
+
+		if len(data) != RecordLength {
+			return nil, &cnab.LineError{Line: r.line, Column: 1, Err: fmt.Errorf("%w: %d positions, want %d", cnab.ErrInvalidLength, len(data), RecordLength)}
+		}
+
+		line := cnab.NewLine(r.line, data)
+		kind := data[0]
+
+		if r.header == nil && kind != Header {
+			return nil, &cnab.LineError{Line: r.line, Column: 1, Field: "record type", Err: fmt.Errorf("%w: the file must start with a header", cnab.ErrUnexpectedRecord)}
+		}
+
+		switch kind {
+		case Header:
+			if r.header != nil {
+				return nil, &cnab.LineError{Line: r.line, Column: 1, Field: "record type", Err: fmt.Errorf("%w: second header", cnab.ErrUnexpectedRecord)}
+			}
+
+			if err := r.readHeader(line); err != nil {
+				return nil, err
+			}
+		case Detail:
+			return r.readDetail(line)
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+func (r *Reader) readHeader(line *cnab.Line) error {
+	if operation := line.Raw("operation", 2, 2); operation != "2" {
+		line.Fail("operation", 2, fmt.Errorf("%w: %q is not a retorno", cnab.ErrInvalidValue, operation))
+		return line.Err()
+	}
+
+	bankCode := line.Digits("bank code", 77, 79)
+	if err := line.Err(); err != nil {
+		return err
+	}
+
+	bank, ok := Lookup(bankCode)
+	if !ok {
+		line.Fail("bank code", 77, fmt.Errorf("%w: %q", ErrUnsupportedBank, bankCode))
+		return line.Err()
+	}
+
+	values := read(line, bank.ReturnHeader)
+	if err := line.Err(); err != nil {
+		return err
+	}
+
+	r.bank = bank
+	r.header = &cnab.Header{
+		BankCode: bankCode,
+		Company: cnab.Company{
+			Name:     text(values, "company name"),
+			Document: strings.TrimLeft(text(values, "company document"), "0"),
+			Account: cnab.Account{
+				Agreement: text(values, "company code"),
+				Agency:    text(values, "agency"),
+				Number:    text(values, "account"),
+				Digit:     text(values, "account digit"),
+			},
+		},
+		CreatedAt: date(values, "creation date"),
+	}
+
+	if sequence, err := strconv.Atoi(text(values, "sequence")); err == nil {
+		r.header.Sequence = sequence
+	}
+
+	return nil
+}
+
+func (r *Reader) readDetail(line *cnab.Line) (*cnab.Return, error) {
+	values := read(line, r.bank.ReturnDetail)
+	if err := line.Err(); err != nil {
+		return nil, err
+	}
+
+	title := &cnab.Return{
+		Line:       r.line,
+		BankCode:   r.bank.Code,
+		Occurrence: cnab.Occurrence(text(values, "occurrence")),
+		Account: cnab.Account{
+			Agency: text(values, "agency"),
+			Number: text(values, "account"),
+			Digit:  text(values, "account digit"),
+			Wallet: text(values, "wallet"),
+		},
+		OurNumber:        text(values, "our number"),
+		OurNumberDigit:   text(values, "our number digit"),
+		DocumentNumber:   text(values, "document number"),
+		CompanyID:        text(values, "company ID"),
+		DueDate:          date(values, "due date"),
+		Amount:           money(values, "amount"),
+		CollectingBank:   text(values, "collecting bank"),
+		CollectingAgency: text(values, "collecting agency"),
+		Payer:            cnab.Payer{Name: text(values, "payer name")},
+		Tariff:           money(values, "tariff"),
+		Interest:         money(values, "interest") + money(values, "fine"),
+		Discount:         money(values, "discount"),
+		Rebate:           money(values, "rebate"),
+		IOF:              money(values, "IOF"),
+		PaidAmount:       money(values, "paid amount"),
+		CreditedAmount:   money(values, "credited amount"),
+		OtherExpenses:    money(values, "other expenses"),
+		OtherCredits:     money(values, "other credits"),
+		OccurrenceDate:   date(values, "occurrence date"),
+		CreditDate:       date(values, "credit date"),
+	}
+
+	reasons := text(values, "reasons")
+	for i := 0; i+2 <= len(reasons); i += 2 {
+		if reason := strings.TrimSpace(reasons[i : i+2]); reason != "" && reason != "00" {
+			title.Reasons = append(title.Reasons, reason)
+		}
+	}
+
+	if r.bank.ParseReturn != nil {
+		r.bank.ParseReturn(values, title)
+	}
+
+	cnab.Link(title, r.bank.FreeField)
+
+	return title, nil
+}
+
+// read reads the fields of a record. Digits fields are kept as strings, with their zeros, and
+// read as empty when blank.
+func read(line *cnab.Line, fields Fields) Values {
+	values := Values{}
+
+	for _, name := range fields.names() {
+		field := fields[name]
+
+		switch field.Format {
+		case Alpha:
+			values[name] = line.Alpha(name, field.Start, field.End)
+		case Digits:
+			if strings.TrimSpace(line.Raw(name, field.Start, field.End)) == "" {
+				values[name] = ""
+			} else {
+				values[name] = line.Digits(name, field.Start, field.End)
+			}
+		case Money:
+			values[name] = line.Money(name, field.Start, field.End)
+		case Rate:
+			values[name] = float64(line.Number(name, field.Start, field.End)) / 100
+		case Date:
+			values[name] = line.Date(name, field.Start, field.End)
+		case ISODate:
+			values[name] = isoDate(line, name, field)
+		}
+	}
+
+	return values
+}
+
+func isoDate(line *cnab.Line, name string, field Field) time.Time {
+	value := line.Raw(name, field.Start, field.End)
+	if strings.Trim(value, "0 ") == "" {
+		return time.Time{}
+	}
+
+	date, err := time.Parse("20060102", value)
+	if err != nil {
+		line.Fail(name, field.Start, fmt.Errorf("%w: %q is not a date", cnab.ErrInvalidValue, value))
+	}
+
+	return date
+}
+
+func text(values Values, name string) string {
+	value, _ := values[name].(string)
+	return value
+}
+
+func money(values Values, name string) utils.Money {
+	value, _ := values[name].(utils.Money)
+	return value
+}
+
+func date(values Values, name string) time.Time {
+	value, _ := values[name].(time.Time)
+	return value
+}
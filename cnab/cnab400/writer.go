@@ -0,0 +1,308 @@
+package cnab400
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/banks"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/utils"
+	"io"
+	"math"
+	"time"
+)
+
+// Write writes remittance as a CNAB 400 remessa in the layout of its bank: a header, one
+// detail per title and a trailer. Records end with CRLF.
+func Write(w io.Writer, remittance *cnab.Remittance) error {
+	bank, ok := Lookup(remittance.BankCode)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedBank, remittance.BankCode)
+	}
+
+	lines, err := records(bank, remittance)
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := buffered.WriteString(line + "\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return buffered.Flush()
+}
+
+func records(bank Bank, remittance *cnab.Remittance) ([]string, error) {
+	if err := checkRemittance(remittance); err != nil {
+		return nil, err
+	}
+
+	createdAt := remittance.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	values := fileValues(bank, remittance, createdAt, 1)
+	if bank.HeaderValues != nil {
+		if err := bank.HeaderValues(values, remittance); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRemittance, err)
+		}
+	}
+
+	header, err := record(bank.Header, values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrInvalidRemittance, err)
+	}
+
+	lines := []string{header}
+
+	var total utils.Money
+	for i, title := range remittance.Titles {
+		detail, err := titleDetail(bank, remittance, title, createdAt, len(lines)+1)
+		if err != nil {
+			return nil, fmt.Errorf("%w: title %d: %w", ErrInvalidTitle, i+1, err)
+		}
+
+		lines = append(lines, detail)
+		total += title.Amount
+	}
+
+	values = fileValues(bank, remittance, createdAt, len(lines)+1)
+	values["titles"] = len(remittance.Titles)
+	values["total"] = total
+
+	trailer, err := record(bank.Trailer, values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: trailer: %w", ErrInvalidRemittance, err)
+	}
+
+	return append(lines, trailer), nil
+}
+
+func checkRemittance(remittance *cnab.Remittance) error {
+	switch {
+	case remittance.Company.Name == "":
+		return fmt.Errorf("%w: missing company name", ErrInvalidRemittance)
+	case cnab.PersonType(remittance.Company.Document) == 0:
+		return fmt.Errorf("%w: missing company document", ErrInvalidRemittance)
+	case remittance.Sequence <= 0:
+		return fmt.Errorf("%w: file sequence must be positive", ErrInvalidRemittance)
+	}
+
+	return nil
+}
+
+// fileValues returns the values every record of remittance can hold
+func fileValues(bank Bank, remittance *cnab.Remittance, createdAt time.Time, sequence int) Values {
+	company := remittance.Company
+
+	values := Values{
+		"company document type": cnab.PersonType(company.Document),
+		"company document":      utils.OnlyNumbers(company.Document),
+		"company name":          company.Name,
+		"company code":          company.Account.Agreement,
+		"agency":                company.Account.Agency,
+		"account":               company.Account.Number,
+		"account digit":         company.Account.Digit,
+		"wallet":                company.Account.Wallet,
+		"bank code":             bank.Code,
+		"creation date":         createdAt,
+		"sequence":              remittance.Sequence,
+		"record sequence":       sequence,
+	}
+
+	if registered, err := banks.ByCode(bank.Code); err == nil {
+		values["bank name"] = registered.ShortName
+	}
+
+	return values
+}
+
+func titleDetail(bank Bank, remittance *cnab.Remittance, title cnab.Title, createdAt time.Time, sequence int) (string, error) {
+	if err := checkTitle(title); err != nil {
+		return "", err
+	}
+
+	values := fileValues(bank, remittance, createdAt, sequence)
+
+	movement := title.Movement
+	if movement == "" {
+		movement = cnab.MovementRegister
+	}
+
+	kind := title.Kind
+	if kind == "" {
+		kind = cnab.KindDuplicataMercantil
+	}
+
+	code, ok := bank.Kinds[kind]
+	if !ok {
+		code = bank.Kinds[cnab.KindOther]
+	}
+
+	acceptance := "N"
+	if title.Accepted {
+		acceptance = "A"
+	}
+
+	issueDate := title.IssueDate
+	if issueDate.IsZero() {
+		issueDate = createdAt
+	}
+
+	var discount cnab.Charge
+	if len(title.Discounts) > 0 {
+		discount = title.Discounts[0]
+	}
+
+	values["movement"] = string(movement)
+	values["our number"] = title.OurNumber
+	values["our number digit"] = title.OurNumberDigit
+	values["document number"] = title.DocumentNumber
+	values["company ID"] = title.CompanyID
+	values["kind"] = code
+	values["acceptance"] = acceptance
+	values["issue date"] = issueDate
+	values["due date"] = title.DueDate
+	values["amount"] = title.Amount
+	values["interest"] = dailyInterest(title)
+	values["interest date"] = title.Interest.Date
+	values["discount"] = chargeAmount(title.Amount, discount)
+	values["discount date"] = discount.Date
+	values["IOF"] = title.IOF
+	values["rebate"] = title.Rebate
+	values["protest days"] = title.ProtestDays
+	values["payer document type"] = cnab.PersonType(title.Payer.Document)
+	values["payer document"] = utils.OnlyNumbers(title.Payer.Document)
+	values["payer name"] = title.Payer.Name
+	values["payer address"] = title.Payer.Address
+	values["payer district"] = title.Payer.District
+	values["payer postal code"] = utils.OnlyNumbers(title.Payer.PostalCode)
+	values["payer city"] = title.Payer.City
+	values["payer state"] = title.Payer.State
+
+	if title.Fine.Code == cnab.ChargePercent {
+		values["fine rate"] = title.Fine.Rate
+		values["fine date"] = title.Fine.Date
+	}
+
+	if len(title.Messages) > 0 {
+		values["message"] = title.Messages[0]
+	}
+
+	if title.Guarantor != nil {
+		values["guarantor document type"] = cnab.PersonType(title.Guarantor.Document)
+		values["guarantor document"] = utils.OnlyNumbers(title.Guarantor.Document)
+		values["guarantor name"] = title.Guarantor.Name
+	}
+
+	if bank.DetailValues != nil {
+		if err := bank.DetailValues(values, remittance, title); err != nil {
+			return "", err
+		}
+	}
+
+	return record(bank.Detail, values)
+}
+
+func checkTitle(title cnab.Title) error {
+	switch {
+	case title.OurNumber == "":
+		return fmt.Errorf("missing nosso número")
+	case title.DueDate.IsZero():
+		return fmt.Errorf("missing due date")
+	case title.Amount <= 0:
+		return fmt.Errorf("amount must be positive")
+	case title.Payer.Name == "" || cnab.PersonType(title.Payer.Document) == 0:
+		return fmt.Errorf("missing payer name or document")
+	case title.Fine.Code == cnab.ChargeAmount:
+		return fmt.Errorf("CNAB 400 fines must be a percentage")
+	}
+
+	return nil
+}
+
+// dailyInterest returns the interest per day of title: its amount, or a thirtieth of its
+// monthly rate
+func dailyInterest(title cnab.Title) utils.Money {
+	switch title.Interest.Code {
+	case cnab.ChargeAmount:
+		return title.Interest.Value
+	case cnab.ChargePercent:
+		return utils.Money(math.Round(float64(title.Amount) * title.Interest.Rate / 100 / 30))
+	default:
+		return 0
+	}
+}
+
+// chargeAmount returns the amount of a discount on amount
+func chargeAmount(amount utils.Money, c cnab.Charge) utils.Money {
+	switch c.Code {
+	case cnab.ChargeAmount:
+		return c.Value
+	case cnab.ChargePercent:
+		return utils.Money(math.Round(float64(amount) * c.Rate / 100))
+	default:
+		return 0
+	}
+}
+
+// record writes values to the fields of a record
+func record(fields Fields, values Values) (string, error) {
+	record := cnab.NewRecord(RecordLength)
+
+	for _, name := range fields.names() {
+		field := fields[name]
+
+		var value any = field.Value
+		if field.Value == "" {
+			value = values[name]
+		}
+
+		if err := put(record, name, field, value); err != nil {
+			return "", err
+		}
+	}
+
+	return record.String(), record.Err()
+}
+
+// put writes value to field in the field's format. Missing values write zeros, or blanks in
+// Alpha fields.
+func put(record *cnab.Record, name string, field Field, value any) error {
+	start, end := field.Start, field.End
+
+	switch v := value.(type) {
+	case nil:
+		if field.Format != Alpha {
+			record.Digits(name, start, end, "0")
+		}
+	case string:
+		switch {
+		case field.Format == Alpha:
+			record.Alpha(name, start, end, v)
+		case v == "":
+			record.Digits(name, start, end, "0")
+		default:
+			record.Digits(name, start, end, v)
+		}
+	case int:
+		record.Number(name, start, end, int64(v))
+	case utils.Money:
+		record.Money(name, start, end, v)
+	case float64:
+		record.Rate(name, start, end, v, 2)
+	case time.Time:
+		if field.Format == ISODate && !v.IsZero() {
+			record.Digits(name, start, end, v.Format("20060102"))
+		} else {
+			record.Date(name, start, end, v)
+		}
+	default:
+		return &cnab.FieldError{Field: name, Start: start, End: end, Value: fmt.Sprint(v), Err: cnab.ErrInvalidValue}
+	}
+
+	return nil
+}
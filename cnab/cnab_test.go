@@ -0,0 +1,151 @@
+package cnab
+
+import (
+	"errors"
+	"github.com/fonini/go-boleto-utils/utils"
+	"testing"
+	"time"
+)
+
+func TestValues_Record(t *testing.T) {
+	date := time.Date(2024, 11, 20, 14, 5, 9, 0, time.UTC)
+
+	tests := []struct {
+		fill func(r *Record)
+		want string
+		err  error
+	}{
+		{func(r *Record) { r.Alpha("name", 1, 10, "João Ção") }, "JOAO CAO  ", nil},
+		{func(r *Record) { r.Alpha("name", 3, 6, "Padaria") }, "  PADA    ", nil},
+		{func(r *Record) { r.Digits("agency", 1, 5, "123") }, "00123     ", nil},
+		{func(r *Record) { r.Number("sequence", 6, 10, 42) }, "     00042", nil},
+		{func(r *Record) { r.Money("amount", 1, 10, 116037) }, "0000116037", nil},
+		{func(r *Record) { r.Rate("rate", 1, 5, 1.5, 2) }, "00150     ", nil},
+		{func(r *Record) { r.Date("date", 1, 8, date) }, "20112024  ", nil},
+		{func(r *Record) { r.Date("date", 1, 6, date) }, "201124    ", nil},
+		{func(r *Record) { r.Date("date", 1, 8, time.Time{}) }, "00000000  ", nil},
+		{func(r *Record) { r.Time("time", 5, 10, date) }, "    140509", nil},
+		{func(r *Record) { r.Time("time", 5, 9, date) }, "", ErrFieldOverflow},
+		{func(r *Record) { r.Time("time", 3, 10, date) }, "", ErrInvalidValue},
+		{func(r *Record) { r.Digits("agency", 1, 3, "1234") }, "", ErrFieldOverflow},
+		{func(r *Record) { r.Digits("agency", 1, 5, "12-3") }, "", ErrInvalidValue},
+		{func(r *Record) { r.Number("sequence", 1, 5, -1) }, "", ErrInvalidValue},
+		{func(r *Record) { r.Alpha("name", 8, 12, "x") }, "", ErrFieldOverflow},
+		{func(r *Record) {
+			r.Digits("first", 1, 2, "123")
+			r.Digits("second", 3, 4, "x")
+		}, "", ErrFieldOverflow},
+	}
+
+	for i, tt := range tests {
+		record := NewRecord(10)
+		tt.fill(record)
+
+		if err := record.Err(); !errors.Is(err, tt.err) {
+			t.Errorf("record %d returned error %v, want %v", i, err, tt.err)
+			continue
+		}
+
+		if tt.err == nil && record.String() != tt.want {
+			t.Errorf("record %d = %q, want %q", i, record.String(), tt.want)
+		}
+	}
+
+	record := NewRecord(10)
+	record.Digits("agency", 1, 3, "1234")
+
+	var fieldErr *FieldError
+	if !errors.As(record.Err(), &fieldErr) || fieldErr.Field != "agency" || fieldErr.Start != 1 || fieldErr.End != 3 {
+		t.Errorf("Err() = %v, want a FieldError for agency", record.Err())
+	}
+}
+
+func TestValues_PersonType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"123.456.789-09", 1},
+		{"12.345.678/0001-95", 2},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := PersonType(tt.input); got != tt.want {
+			t.Errorf("PersonType(%v) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestValues_Line(t *testing.T) {
+	line := NewLine(4, "00123  JOAO 20112024000000000116037        0012X")
+
+	if got := line.Digits("agency", 1, 5); got != "00123" {
+		t.Errorf("Digits = %q", got)
+	}
+
+	if got := line.Alpha("name", 6, 12); got != "JOAO" {
+		t.Errorf("Alpha = %q", got)
+	}
+
+	if got := line.Date("date", 13, 20); !got.Equal(time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v", got)
+	}
+
+	if got := line.Money("amount", 21, 35); got != 116037 {
+		t.Errorf("Money = %d", got)
+	}
+
+	if got := line.Number("blank", 36, 43); got != 0 || line.Err() != nil {
+		t.Errorf("Number of a blank field = %d, %v", got, line.Err())
+	}
+
+	line.Number("count", 44, 48)
+	line.Digits("agency", 1, 5)
+
+	var lineErr *LineError
+	if !errors.As(line.Err(), &lineErr) || !errors.Is(lineErr, ErrInvalidValue) || lineErr.Line != 4 || lineErr.Column != 44 || lineErr.Field != "count" {
+		t.Errorf("Err() = %v, want a LineError for count", line.Err())
+	}
+
+	if want := `line 4, column 44 (count): invalid field value: "0012X" is not numeric`; line.Err().Error() != want {
+		t.Errorf("Err() = %q, want %q", line.Err(), want)
+	}
+
+	short := NewLine(1, "123")
+	if short.Raw("name", 2, 8); !errors.Is(short.Err(), ErrInvalidLength) {
+		t.Errorf("Raw past the end returned %v", short.Err())
+	}
+}
+
+func TestValues_Link(t *testing.T) {
+	title := func(agency, ourNumber string, amount utils.Money) *Return {
+		return &Return{
+			BankCode:  "237",
+			Account:   Account{Agency: agency, Number: "000000098765", Wallet: "17"},
+			OurNumber: ourNumber,
+			DueDate:   time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC),
+			Amount:    amount,
+		}
+	}
+
+	tests := []struct {
+		title     *Return
+		freeField func(r *Return) (string, bool)
+		want      string
+	}{
+		{title("04321", "00000123456", 116037), BradescoFreeField, "23794990600001160374321170000012345600987650"},
+		{title("04321", "123456", 116037), BradescoFreeField, ""},
+		{title("123456", "00000123456", 116037), BradescoFreeField, ""},
+		{title("04321", "00000123456", 0), BradescoFreeField, ""},
+		{title("04321", "00000123456", 116037), nil, ""},
+	}
+
+	for i, tt := range tests {
+		Link(tt.title, tt.freeField)
+
+		if tt.title.Barcode != tt.want {
+			t.Errorf("title %d has barcode %q, want %q", i, tt.title.Barcode, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Package cnabtest holds the fixtures shared by the tests of the CNAB packages
+package cnabtest
+
+import (
+	"bytes"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// CreatedAt is the creation date of the remessas returned by Remittance
+var CreatedAt = time.Date(2024, 11, 10, 8, 30, 0, 0, time.UTC)
+
+// Payer returns the payer of the titles of the tests
+func Payer() cnab.Payer {
+	return cnab.Payer{
+		Name:       "José da Silva",
+		Document:   "123.456.789-09",
+		Address:    "Rua das Flores, 100",
+		District:   "Centro",
+		PostalCode: "01310-100",
+		City:       "São Paulo",
+		State:      "SP",
+	}
+}
+
+// Remittance returns the seventh remessa of a bakery to the bank, created at CreatedAt
+func Remittance(bankCode string, account cnab.Account, titles ...cnab.Title) *cnab.Remittance {
+	return &cnab.Remittance{
+		BankCode: bankCode,
+		Company: cnab.Company{
+			Name:     "Padaria Pão Quente Ltda",
+			Document: "12.345.678/0001-95",
+			Account:  account,
+		},
+		Sequence:  7,
+		CreatedAt: CreatedAt,
+		Titles:    titles,
+	}
+}
+
+// Lines writes r with write and returns the lines of the file, failing the test unless
+// every line has length positions and ends with CRLF
+func Lines(t testing.TB, write func(io.Writer, *cnab.Remittance) error, r *cnab.Remittance, length int) []string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := write(&buf, r); err != nil {
+		t.Fatalf("Write returned %v", err)
+	}
+
+	if !strings.HasSuffix(buf.String(), "\r\n") {
+		t.Fatalf("Write did not end the file with CRLF")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	for i, line := range lines {
+		if len(line) != length {
+			t.Fatalf("line %d has %d positions", i+1, len(line))
+		}
+	}
+
+	return lines
+}
+
+// Line returns a record of length positions with value written at each position of fields
+func Line(length int, fields map[int]string) string {
+	data := []byte(strings.Repeat(" ", length))
+	for start, value := range fields {
+		copy(data[start-1:], value)
+	}
+
+	return string(data)
+}
+
+// Field returns the positions start to end, 1-based and inclusive, of line
+func Field(line string, start, end int) string {
+	return line[start-1 : end]
+}
@@ -0,0 +1,121 @@
+package layout
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"io"
+	"strings"
+)
+
+// Writer writes the records of a file in a layout, each followed by CRLF
+type Writer struct {
+	layout *Layout
+	w      *bufio.Writer
+	err    error
+}
+
+// NewWriter returns a writer of files in the layout to w. Call Flush after the last record.
+// When the layout isn't valid, Write returns ErrInvalidLayout.
+func (l *Layout) NewWriter(w io.Writer) *Writer {
+	checked, err := l.Checked()
+
+	return &Writer{layout: checked, w: bufio.NewWriter(w), err: err}
+}
+
+// Write writes the record called name holding values
+func (w *Writer) Write(name string, values Values) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	record, ok := w.layout.Record(name)
+	if !ok {
+		return fmt.Errorf("%w: %s has no record %q", ErrUnknownRecord, w.layout.Name, name)
+	}
+
+	line, err := record.Write(values)
+	if err != nil {
+		return fmt.Errorf("record %s: %w", name, err)
+	}
+
+	_, err = w.w.WriteString(line + "\r\n")
+
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Entry is a record read from a file: its line number, the name of its record and the
+// values of its fields
+type Entry struct {
+	Line   int
+	Record string
+	Values Values
+}
+
+// Reader reads the records of a file in a layout
+type Reader struct {
+	layout  *Layout
+	scanner *bufio.Scanner
+	line    int
+	err     error
+}
+
+// NewReader returns a reader of the file in r. When the layout isn't valid, Read returns
+// ErrInvalidLayout.
+func (l *Layout) NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024), 64*1024)
+
+	checked, err := l.Checked()
+
+	return &Reader{layout: checked, scanner: scanner, err: err}
+}
+
+// Read returns the next record of the file, and io.EOF after the last. Lines of the wrong
+// length, lines no record matches and fields that can't be read return a *cnab.LineError;
+// reading can go on after one, from the next line.
+func (r *Reader) Read() (*Entry, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimRight(r.scanner.Text(), "\r")
+
+		if line == "" {
+			continue
+		}
+
+		if len(line) != r.layout.Length {
+			return nil, &cnab.LineError{Line: r.line, Column: 1, Err: fmt.Errorf("%w: %d positions, want %d", cnab.ErrInvalidLength, len(line), r.layout.Length)}
+		}
+
+		record, ok := r.layout.Match(line)
+		if !ok {
+			return nil, &cnab.LineError{Line: r.line, Column: 1, Err: fmt.Errorf("%w: no record of %s matches", ErrUnknownRecord, r.layout.Name)}
+		}
+
+		values, err := record.Read(line)
+		if err != nil {
+			if fieldErr, ok := err.(*cnab.FieldError); ok {
+				return nil, &cnab.LineError{Line: r.line, Column: fieldErr.Start, Field: fieldErr.Field, Err: fmt.Errorf("%w: %q", fieldErr.Err, fieldErr.Value)}
+			}
+
+			return nil, &cnab.LineError{Line: r.line, Column: 1, Err: err}
+		}
+
+		return &Entry{Line: r.line, Record: record.Name, Values: values}, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
@@ -0,0 +1,413 @@
+// Package layout describes CNAB records declaratively. A Layout lists the records of a file
+// and the name, position, type and format of their fields; it is read from JSON, so a bank's
+// layout can be shipped as a data file. The layouts in data/ are bundled.
+//
+// The cnab240 and cnab400 packages write and read their records with the bundled layouts:
+// the FEBRABAN CNAB 240 remessa and retorno, the CNAB 240 variants of Banco do Brasil, Caixa,
+// Bradesco, Sicredi and Sicoob, and the CNAB 400 layouts of Bradesco, Itaú and Sicredi.
+//
+// A layout file looks like:
+//
+//	{
+//	  "name": "bradesco-400-remessa",
+//	  "length": 400,
+//	  "records": [{
+//	    "name": "header",
+//	    "fields": [
+//	      {"name": "record type", "start": 1, "length": 1, "type": "numeric", "default": "0", "key": true},
+//	      {"name": "creation date", "start": 95, "length": 6, "type": "date", "format": "DDMMAA"},
+//	      {"name": "fine rate", "start": 67, "length": 4, "type": "decimal", "decimals": 2}
+//	    ]
+//	  }]
+//	}
+//
+// Fields with "key" set identify a record when a file is read: a line is the first record
+// whose key fields hold their defaults.
+package layout
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"sync"
+)
+
+//go:embed data/*.json
+var data embed.FS
+
+var (
+	// ErrInvalidLayout is returned when a layout file can't be read or describes fields that
+	// don't fit its records
+	ErrInvalidLayout = errors.New("invalid CNAB layout")
+	// ErrUnknownRecord is returned for records a layout doesn't have, or lines no record of
+	// the layout matches
+	ErrUnknownRecord = errors.New("unknown record")
+)
+
+// Type is the type of the value of a field
+type Type string
+
+const (
+	// Alpha fields hold text, left-aligned and padded with blanks
+	Alpha Type = "alpha"
+	// Numeric fields hold digits, right-aligned and padded with zeros
+	Numeric Type = "numeric"
+	// Money fields hold an amount with two implied decimal places, or Decimals when set
+	Money Type = "money"
+	// Decimal fields hold a number, such as a rate, with implied decimal places
+	Decimal Type = "decimal"
+	// Date fields hold a date in Format: DDMMAAAA (the default), DDMMAA or AAAAMMDD
+	Date Type = "date"
+	// Time fields hold a time of day as HHMMSS
+	Time Type = "time"
+)
+
+// dateFormats maps the date formats of the bank manuals to Go layouts
+var dateFormats = map[string]string{
+	"DDMMAAAA": "02012006",
+	"DDMMAA":   "020106",
+	"AAAAMMDD": "20060102",
+	"HHMMSS":   "150405",
+}
+
+// Field is a field of a record. Start is 1-based, as in the bank manuals. Padding is the
+// character that fills the field, a blank for Alpha fields and a zero for the others
+// unless set. Default is written when there is no value for the field.
+type Field struct {
+	Name     string `json:"name"`
+	Start    int    `json:"start"`
+	Length   int    `json:"length"`
+	Type     Type   `json:"type"`
+	Padding  string `json:"padding,omitempty"`
+	Default  string `json:"default,omitempty"`
+	Format   string `json:"format,omitempty"`
+	Decimals int    `json:"decimals,omitempty"`
+	Key      bool   `json:"key,omitempty"`
+}
+
+// End returns the last position of the field
+func (f Field) End() int {
+	return f.Start + f.Length - 1
+}
+
+// Record is a kind of record of a layout: a header, a detail segment or a trailer. Its
+// methods write and read records of the length of a layout read by Load or Checked.
+type Record struct {
+	Name   string  `json:"name"`
+	Fields []Field `json:"fields"`
+
+	length int
+}
+
+// Layout is the records of a CNAB file, all Length positions long
+type Layout struct {
+	Name    string    `json:"name"`
+	Length  int       `json:"length"`
+	Records []*Record `json:"records"`
+}
+
+// Values holds the values of the fields of a record by name. Alpha and Numeric values are
+// strings (Numeric ones may also be ints), Money values utils.Money, Decimal values float64
+// and Date and Time values time.Time.
+type Values map[string]any
+
+var (
+	mu      sync.RWMutex
+	layouts = map[string]*Layout{}
+)
+
+func init() {
+	entries, err := data.ReadDir("data")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		f, err := data.Open(path.Join("data", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		l, err := Load(f)
+		f.Close()
+
+		if err != nil {
+			panic(fmt.Sprintf("%s: %v", entry.Name(), err))
+		}
+
+		layouts[l.Name] = l
+	}
+}
+
+// Register checks l and adds it to the layouts Lookup finds, replacing any layout of the
+// same name
+func Register(l *Layout) error {
+	if err := l.check(); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	layouts[l.Name] = l
+
+	return nil
+}
+
+// Lookup returns the bundled or registered layout called name
+func Lookup(name string) (*Layout, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	l, ok := layouts[name]
+
+	return l, ok
+}
+
+// Names returns the names of the bundled and registered layouts, sorted
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(layouts))
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Load reads a layout from JSON and checks that its fields fit its records
+func Load(r io.Reader) (*Layout, error) {
+	var l Layout
+	if err := json.NewDecoder(r).Decode(&l); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLayout, err)
+	}
+
+	if err := l.check(); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+// LoadFile reads a layout from the JSON file at path
+func LoadFile(path string) (*Layout, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Record returns the record of the layout called name
+func (l *Layout) Record(name string) (*Record, bool) {
+	for _, record := range l.Records {
+		if record.Name == name {
+			return record, true
+		}
+	}
+
+	return nil, false
+}
+
+// Checked checks l like Load does and returns it ready to write and read records. Layouts
+// read by Load or passed to Register are returned as they are, and layouts built as struct
+// literals as a checked copy. NewWriter, NewReader and Match call it themselves.
+func (l *Layout) Checked() (*Layout, error) {
+	if l.sized() {
+		return l, nil
+	}
+
+	c := &Layout{Name: l.Name, Length: l.Length, Records: make([]*Record, len(l.Records))}
+	for i, record := range l.Records {
+		if record == nil {
+			return nil, fmt.Errorf("%w: %s: record %d is nil", ErrInvalidLayout, l.Name, i+1)
+		}
+
+		c.Records[i] = &Record{Name: record.Name, Fields: record.Fields}
+	}
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// sized reports whether check has sized the records of l
+func (l *Layout) sized() bool {
+	for _, record := range l.Records {
+		if record == nil || record.length != l.Length {
+			return false
+		}
+	}
+
+	return len(l.Records) > 0
+}
+
+// Match returns the first record whose key fields hold their defaults in line. It matches
+// nothing when l isn't valid.
+func (l *Layout) Match(line string) (*Record, bool) {
+	l, err := l.Checked()
+	if err != nil {
+		return nil, false
+	}
+
+	for _, record := range l.Records {
+		if record.matches(line) {
+			return record, true
+		}
+	}
+
+	return nil, false
+}
+
+func (l *Layout) check() error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidLayout, l.Name, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case l.Name == "":
+		return fmt.Errorf("%w: missing name", ErrInvalidLayout)
+	case l.Length <= 0:
+		return invalid("record length must be positive")
+	case len(l.Records) == 0:
+		return invalid("no records")
+	}
+
+	names := map[string]bool{}
+	for _, record := range l.Records {
+		if record.Name == "" || names[record.Name] {
+			return invalid("missing or repeated record name %q", record.Name)
+		}
+		names[record.Name] = true
+
+		if len(l.Records) > 1 && !record.keyed() {
+			return invalid("record %s: no key field", record.Name)
+		}
+
+		if err := record.check(l.Length); err != nil {
+			return invalid("record %s: %v", record.Name, err)
+		}
+
+		record.length = l.Length
+	}
+
+	return nil
+}
+
+func (r *Record) keyed() bool {
+	for _, field := range r.Fields {
+		if field.Key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Record) check(length int) error {
+	fields := append([]Field(nil), r.Fields...)
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Start < fields[j].Start
+	})
+
+	names := map[string]bool{}
+	for i, field := range fields {
+		if field.Name == "" || names[field.Name] {
+			return fmt.Errorf("missing or repeated field name %q", field.Name)
+		}
+		names[field.Name] = true
+
+		if field.Start < 1 || field.Length < 1 || field.End() > length {
+			return fmt.Errorf("field %s (%d-%d) doesn't fit %d positions", field.Name, field.Start, field.End(), length)
+		}
+
+		if i > 0 && fields[i-1].End() >= field.Start {
+			return fmt.Errorf("fields %s and %s overlap", fields[i-1].Name, field.Name)
+		}
+
+		if field.Padding != "" && len(field.Padding) != 1 {
+			return fmt.Errorf("field %s: padding must be one character", field.Name)
+		}
+
+		if len(field.Default) > field.Length {
+			return fmt.Errorf("field %s: default %q doesn't fit", field.Name, field.Default)
+		}
+
+		if field.Key && field.Default == "" {
+			return fmt.Errorf("key field %s has no default", field.Name)
+		}
+
+		if err := field.checkType(); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (f Field) checkType() error {
+	switch f.Type {
+	case Alpha, Numeric:
+	case Money, Decimal:
+		if f.Decimals < 0 {
+			return fmt.Errorf("negative decimal places")
+		}
+	case Date:
+		if goLayout, ok := dateFormats[f.format()]; !ok || len(goLayout) != f.Length || f.format() == "HHMMSS" {
+			return fmt.Errorf("date format %q doesn't fit %d positions", f.format(), f.Length)
+		}
+	case Time:
+		if f.Length != 6 {
+			return fmt.Errorf("times take 6 positions")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", f.Type)
+	}
+
+	return nil
+}
+
+// format returns the date format of the field, DDMMAAAA unless set
+func (f Field) format() string {
+	switch {
+	case f.Format != "":
+		return f.Format
+	case f.Type == Time:
+		return "HHMMSS"
+	default:
+		return "DDMMAAAA"
+	}
+}
+
+// decimals returns the implied decimal places of the field, two for Money unless set
+func (f Field) decimals() int {
+	if f.Type == Money && f.Decimals == 0 {
+		return 2
+	}
+
+	return f.Decimals
+}
+
+// padding returns the character that fills the field
+func (f Field) padding() byte {
+	switch {
+	case f.Padding != "":
+		return f.Padding[0]
+	case f.Type == Alpha:
+		return ' '
+	default:
+		return '0'
+	}
+}
@@ -0,0 +1,334 @@
+package layout
+
+import (
+	"bytes"
+	"errors"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/utils"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const custom = `{
+  "name": "custom-40",
+  "length": 40,
+  "records": [
+    {
+      "name": "header",
+      "fields": [
+        {"name": "record type", "start": 1, "length": 1, "type": "numeric", "default": "0", "key": true},
+        {"name": "company name", "start": 2, "length": 10, "type": "alpha"},
+        {"name": "creation date", "start": 12, "length": 8, "type": "date", "format": "AAAAMMDD"},
+        {"name": "creation time", "start": 20, "length": 6, "type": "time"},
+        {"name": "version", "start": 26, "length": 3, "type": "alpha", "default": "V2"}
+      ]
+    },
+    {
+      "name": "detail",
+      "fields": [
+        {"name": "record type", "start": 1, "length": 1, "type": "numeric", "default": "1", "key": true},
+        {"name": "our number", "start": 2, "length": 8, "type": "numeric", "padding": " "},
+        {"name": "amount", "start": 10, "length": 10, "type": "money"},
+        {"name": "rate", "start": 20, "length": 6, "type": "decimal", "decimals": 4},
+        {"name": "due date", "start": 26, "length": 6, "type": "date", "format": "DDMMAA"},
+        {"name": "precise amount", "start": 32, "length": 8, "type": "money", "decimals": 3}
+      ]
+    }
+  ]
+}`
+
+func load(t *testing.T) *Layout {
+	t.Helper()
+
+	l, err := Load(strings.NewReader(custom))
+	if err != nil {
+		t.Fatalf("Load returned %v", err)
+	}
+
+	return l
+}
+
+func TestValues_Bundled(t *testing.T) {
+	want := []string{
+		"bradesco-400-remessa", "bradesco-400-retorno",
+		"febraban-240-remessa", "febraban-240-retorno",
+		"itau-400-remessa", "itau-400-retorno",
+		"sicredi-400-remessa", "sicredi-400-retorno",
+	}
+
+	for _, name := range want {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("Lookup(%q) found no layout", name)
+		}
+	}
+
+	l, _ := Lookup("febraban-240-retorno")
+	line := strings.Repeat(" ", 7) + "3     U" + strings.Repeat(" ", 226)
+
+	if record, ok := l.Match(line); !ok || record.Name != "segment U" {
+		t.Errorf("Match(segment U) = %v, %v", record, ok)
+	}
+}
+
+func TestValues_Record(t *testing.T) {
+	l := load(t)
+	createdAt := time.Date(2024, 11, 10, 8, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		record string
+		values Values
+		want   string
+		read   Values
+	}{
+		{
+			"header",
+			Values{"company name": "Padaria Pão Quente", "creation date": createdAt, "creation time": createdAt},
+			"0PADARIA PA20241110083015V2            ",
+			Values{"record type": "0", "company name": "PADARIA PA", "creation date": time.Date(2024, 11, 10, 0, 0, 0, 0, time.UTC), "creation time": time.Date(0, 1, 1, 8, 30, 15, 0, time.UTC), "version": "V2"},
+		},
+		{
+			"detail",
+			Values{"our number": 123456, "amount": utils.Money(116037), "rate": 1.5, "due date": time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC), "precise amount": utils.Money(116037)},
+			"1  123456000011603701500020112401160370",
+			Values{"record type": "1", "our number": "123456", "amount": utils.Money(116037), "rate": 1.5, "due date": time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC), "precise amount": utils.Money(116037)},
+		},
+		{
+			"detail",
+			Values{},
+			"1        000000000000000000000000000000",
+			Values{"record type": "1", "our number": "", "amount": utils.Money(0), "rate": float64(0), "due date": time.Time{}, "precise amount": utils.Money(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		record, _ := l.Record(tt.record)
+
+		got, err := record.Write(tt.values)
+		if err != nil {
+			t.Errorf("Write(%v) returned %v", tt.values, err)
+			continue
+		}
+
+		if got != tt.want+strings.Repeat(" ", 40-len(tt.want)) {
+			t.Errorf("Write(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+
+		values, err := record.Read(got)
+		if err != nil {
+			t.Errorf("Read(%q) returned %v", got, err)
+			continue
+		}
+
+		if diff := cmp.Diff(tt.read, values); diff != "" {
+			t.Errorf("Read(%q) mismatch (-want +got):\n%s", got, diff)
+		}
+	}
+}
+
+func TestValues_RecordErrors(t *testing.T) {
+	l := load(t)
+	detail, _ := l.Record("detail")
+
+	tests := []struct {
+		values Values
+		field  string
+		err    error
+	}{
+		{Values{"our number": "12-34"}, "our number", cnab.ErrInvalidValue},
+		{Values{"our number": 123456789}, "our number", cnab.ErrFieldOverflow},
+		{Values{"amount": utils.Money(-1)}, "amount", cnab.ErrInvalidValue},
+		{Values{"amount": "10,00"}, "amount", cnab.ErrInvalidValue},
+		{Values{"due date": "20/11/2024"}, "due date", cnab.ErrInvalidValue},
+		{Values{"rate": 100.0}, "rate", cnab.ErrFieldOverflow},
+	}
+
+	for _, tt := range tests {
+		_, err := detail.Write(tt.values)
+
+		var fieldErr *cnab.FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != tt.field || !errors.Is(err, tt.err) {
+			t.Errorf("Write(%v) returned %v, want %v in %s", tt.values, err, tt.err, tt.field)
+		}
+	}
+}
+
+func TestValues_RecordCheck(t *testing.T) {
+	l := load(t)
+	detail, _ := l.Record("detail")
+
+	values, errs := detail.Check("1  12A4560000116037015000310224" + strings.Repeat(" ", 9))
+
+	var fields []string
+	for _, err := range errs {
+		var fieldErr *cnab.FieldError
+		if errors.As(err, &fieldErr) && errors.Is(err, cnab.ErrInvalidValue) {
+			fields = append(fields, fieldErr.Field)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"our number", "due date"}, fields); diff != "" {
+		t.Errorf("Check errors mismatch (-want +got):\n%s", diff)
+	}
+
+	if values["amount"] != utils.Money(116037) || values["precise amount"] != utils.Money(0) {
+		t.Errorf("Check values = %v", values)
+	}
+
+	if _, errs := detail.Check("1"); len(errs) != 1 || !errors.Is(errs[0], cnab.ErrInvalidLength) {
+		t.Errorf("Check(1) returned %v, want ErrInvalidLength", errs)
+	}
+}
+
+func TestValues_Load(t *testing.T) {
+	field := func(json string) string {
+		return `{"name": "bad", "length": 10, "records": [{"name": "detail", "fields": [` + json + `]}]}`
+	}
+
+	tests := []string{
+		`{"name": "bad", "length": 10`,
+		`{"length": 10, "records": [{"name": "detail", "fields": []}]}`,
+		`{"name": "bad", "length": 10, "records": []}`,
+		`{"name": "bad", "length": 10, "records": [{"name": "a", "fields": []}, {"name": "b", "fields": []}]}`,
+		field(`{"name": "a", "start": 8, "length": 4, "type": "alpha"}`),
+		field(`{"name": "a", "start": 1, "length": 4, "type": "alpha"}, {"name": "b", "start": 4, "length": 2, "type": "alpha"}`),
+		field(`{"name": "a", "start": 1, "length": 4, "type": "alpha"}, {"name": "a", "start": 5, "length": 2, "type": "alpha"}`),
+		field(`{"name": "a", "start": 1, "length": 4, "type": "text"}`),
+		field(`{"name": "a", "start": 1, "length": 6, "type": "date", "format": "DDMMAAAA"}`),
+		field(`{"name": "a", "start": 1, "length": 4, "type": "alpha", "key": true}`),
+		field(`{"name": "a", "start": 1, "length": 2, "type": "alpha", "default": "ABC"}`),
+		field(`{"name": "a", "start": 1, "length": 2, "type": "numeric", "padding": "00"}`),
+	}
+
+	for _, tt := range tests {
+		if _, err := Load(strings.NewReader(tt)); !errors.Is(err, ErrInvalidLayout) {
+			t.Errorf("Load(%s) returned %v, want ErrInvalidLayout", tt, err)
+		}
+	}
+
+	l := load(t)
+	if err := Register(l); err != nil {
+		t.Fatalf("Register returned %v", err)
+	}
+
+	if got, ok := Lookup("custom-40"); !ok || got != l {
+		t.Errorf("Lookup(custom-40) = %v, %v", got, ok)
+	}
+}
+
+func TestValues_File(t *testing.T) {
+	l := load(t)
+
+	var buf bytes.Buffer
+	w := l.NewWriter(&buf)
+
+	if err := w.Write("header", Values{"company name": "Padaria"}); err != nil {
+		t.Fatalf("Write returned %v", err)
+	}
+
+	if err := w.Write("detail", Values{"our number": "42", "amount": utils.Money(1000)}); err != nil {
+		t.Fatalf("Write returned %v", err)
+	}
+
+	if err := w.Write("trailer", nil); !errors.Is(err, ErrUnknownRecord) {
+		t.Errorf("Write(trailer) returned %v, want ErrUnknownRecord", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush returned %v", err)
+	}
+
+	file := buf.String() + "1      4X" + strings.Repeat("0", 31) + "\r\n" + "2" + strings.Repeat(" ", 39) + "\r\n" + "1\r\n"
+	r := l.NewReader(strings.NewReader(file))
+
+	var records []string
+	for _, want := range []struct {
+		line   int
+		column int
+		field  string
+		err    error
+	}{
+		{3, 2, "our number", cnab.ErrInvalidValue},
+		{4, 1, "", ErrUnknownRecord},
+		{5, 1, "", cnab.ErrInvalidLength},
+	} {
+		for {
+			entry, err := r.Read()
+			if err == nil {
+				records = append(records, entry.Record)
+				continue
+			}
+
+			var lineErr *cnab.LineError
+			if !errors.As(err, &lineErr) || lineErr.Line != want.line || lineErr.Column != want.column || lineErr.Field != want.field || !errors.Is(err, want.err) {
+				t.Errorf("Read returned %v, want %v at line %d, column %d", err, want.err, want.line, want.column)
+			}
+
+			break
+		}
+	}
+
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Read at the end returned %v, want io.EOF", err)
+	}
+
+	if diff := cmp.Diff([]string{"header", "detail"}, records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValues_Literal(t *testing.T) {
+	l := &Layout{
+		Name:   "payments-60",
+		Length: 60,
+		Records: []*Record{{
+			Name: "payment",
+			Fields: []Field{
+				{Name: "barcode", Start: 1, Length: 44, Type: Numeric},
+				{Name: "amount", Start: 45, Length: 10, Type: Money},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	w := l.NewWriter(&buf)
+
+	if err := w.Write("payment", Values{"barcode": "23791990600001160374321090000012345600987650", "amount": utils.Money(116037)}); err != nil {
+		t.Fatalf("Write returned %v", err)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush returned %v", err)
+	}
+
+	want := "23791990600001160374321090000012345600987650" + "0000116037" + strings.Repeat(" ", 6) + "\r\n"
+	if buf.String() != want {
+		t.Errorf("NewWriter wrote %q, want %q", buf.String(), want)
+	}
+
+	entry, err := l.NewReader(strings.NewReader(want)).Read()
+	if err != nil || entry.Values["amount"] != utils.Money(116037) {
+		t.Errorf("Read returned %+v, %v", entry, err)
+	}
+
+	if _, ok := l.Match(strings.TrimSuffix(want, "\r\n")); !ok {
+		t.Errorf("Match found no record")
+	}
+
+	// the records of the literal are left as they are
+	if _, err := l.Records[0].Write(nil); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("Record.Write of an unchecked layout returned %v, want ErrInvalidLayout", err)
+	}
+
+	l.Records[0].Fields[1].Start = 55
+	if err := l.NewWriter(io.Discard).Write("payment", nil); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("Write with a field past the record returned %v, want ErrInvalidLayout", err)
+	}
+
+	if _, err := l.NewReader(strings.NewReader(want)).Read(); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("Read with a field past the record returned %v, want ErrInvalidLayout", err)
+	}
+}
@@ -0,0 +1,268 @@
+package layout
+
+import (
+	"fmt"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/utils"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Write returns the record holding values. Fields without a value hold their default, or
+// are filled with their padding.
+func (r *Record) Write(values Values) (string, error) {
+	if r.length == 0 {
+		return "", r.unsized()
+	}
+
+	data := []byte(strings.Repeat(" ", r.length))
+
+	for _, field := range r.Fields {
+		text, err := field.text(values[field.Name])
+		if err != nil {
+			return "", err
+		}
+
+		copy(data[field.Start-1:field.End()], text)
+	}
+
+	return string(data), nil
+}
+
+// Read returns the values of the fields of line. Blank Numeric fields read as empty
+// strings, and blank or zero Money, Decimal, Date and Time fields as zero values.
+func (r *Record) Read(line string) (Values, error) {
+	values, errs := r.Check(line)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return values, nil
+}
+
+// Check reads line like Read, but goes on after a field that can't be read: it returns the
+// values of the other fields and a *cnab.FieldError for each field that failed
+func (r *Record) Check(line string) (Values, []error) {
+	if r.length == 0 {
+		return nil, []error{r.unsized()}
+	}
+
+	if len(line) != r.length {
+		return nil, []error{fmt.Errorf("%w: %d positions, want %d", cnab.ErrInvalidLength, len(line), r.length)}
+	}
+
+	var errs []error
+
+	values := Values{}
+	for _, field := range r.Fields {
+		value, err := field.value(line[field.Start-1 : field.End()])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		values[field.Name] = value
+	}
+
+	return values, errs
+}
+
+// Field returns the field of the record called name
+func (r *Record) Field(name string) (Field, bool) {
+	for _, field := range r.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+
+	return Field{}, false
+}
+
+// unsized is the error of records of layouts that weren't checked
+func (r *Record) unsized() error {
+	return fmt.Errorf("%w: record %s doesn't belong to a checked layout", ErrInvalidLayout, r.Name)
+}
+
+func (r *Record) matches(line string) bool {
+	if len(line) != r.length {
+		return false
+	}
+
+	for _, field := range r.Fields {
+		if field.Key && line[field.Start-1:field.End()] != field.pad(field.Default) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f Field) fail(value string, err error) error {
+	return &cnab.FieldError{Field: f.Name, Start: f.Start, End: f.End(), Value: value, Err: err}
+}
+
+// pad aligns text in the field, left for Alpha fields and right for the others
+func (f Field) pad(text string) string {
+	padding := strings.Repeat(string(f.padding()), f.Length-len(text))
+	if f.Type == Alpha {
+		return text + padding
+	}
+
+	return padding + text
+}
+
+// text returns the positions of the field holding value
+func (f Field) text(value any) (string, error) {
+	if value == nil {
+		if len(f.Default) > f.Length {
+			return "", f.fail(f.Default, cnab.ErrFieldOverflow)
+		}
+
+		return f.pad(f.Default), nil
+	}
+
+	var text string
+
+	switch v := value.(type) {
+	case string:
+		switch f.Type {
+		case Alpha:
+			text = cnab.Normalize(v)
+			if len(text) > f.Length {
+				text = text[:f.Length]
+			}
+		case Numeric:
+			if utils.OnlyNumbers(v) != v {
+				return "", f.fail(v, cnab.ErrInvalidValue)
+			}
+			text = v
+		default:
+			return "", f.fail(v, cnab.ErrInvalidValue)
+		}
+	case int:
+		return f.number(int64(v))
+	case int64:
+		return f.number(v)
+	case utils.Money:
+		return f.number(int64(v))
+	case float64:
+		if f.Type != Decimal && f.Type != Money {
+			return "", f.fail(fmt.Sprint(v), cnab.ErrInvalidValue)
+		}
+
+		return f.digits(int64(math.Round(v * math.Pow10(f.decimals()))))
+	case time.Time:
+		if f.Type != Date && f.Type != Time {
+			return "", f.fail(v.String(), cnab.ErrInvalidValue)
+		}
+
+		if v.IsZero() && f.Type == Date {
+			return strings.Repeat("0", f.Length), nil
+		}
+
+		text = v.Format(dateFormats[f.format()])
+	default:
+		return "", f.fail(fmt.Sprint(v), cnab.ErrInvalidValue)
+	}
+
+	if len(text) > f.Length {
+		return "", f.fail(text, cnab.ErrFieldOverflow)
+	}
+
+	return f.pad(text), nil
+}
+
+// number writes an integer: as is in Numeric fields, and as centavos in Money fields
+func (f Field) number(n int64) (string, error) {
+	switch f.Type {
+	case Numeric:
+		return f.digits(n)
+	case Money:
+		return f.digits(scale(n, f.decimals()-2))
+	default:
+		return "", f.fail(strconv.FormatInt(n, 10), cnab.ErrInvalidValue)
+	}
+}
+
+func (f Field) digits(n int64) (string, error) {
+	text := strconv.FormatInt(n, 10)
+
+	switch {
+	case n < 0:
+		return "", f.fail(text, cnab.ErrInvalidValue)
+	case len(text) > f.Length:
+		return "", f.fail(text, cnab.ErrFieldOverflow)
+	}
+
+	return f.pad(text), nil
+}
+
+// value reads the value of the field from its positions
+func (f Field) value(text string) (any, error) {
+	blank := strings.TrimSpace(text) == ""
+
+	switch f.Type {
+	case Alpha:
+		return strings.TrimSpace(text), nil
+	case Numeric:
+		digits := strings.TrimLeft(text, " ")
+		if blank {
+			return "", nil
+		}
+
+		if utils.OnlyNumbers(digits) != digits {
+			return nil, f.fail(text, cnab.ErrInvalidValue)
+		}
+
+		return digits, nil
+	case Money, Decimal:
+		if blank {
+			return f.zero(), nil
+		}
+
+		n, err := strconv.ParseInt(strings.TrimLeft(text, " "), 10, 64)
+		if err != nil || n < 0 {
+			return nil, f.fail(text, cnab.ErrInvalidValue)
+		}
+
+		if f.Type == Money {
+			return utils.Money(scale(n, 2-f.decimals())), nil
+		}
+
+		return float64(n) / math.Pow10(f.decimals()), nil
+	default:
+		if strings.Trim(text, "0 ") == "" {
+			return time.Time{}, nil
+		}
+
+		date, err := time.Parse(dateFormats[f.format()], text)
+		if err != nil {
+			return nil, f.fail(text, cnab.ErrInvalidValue)
+		}
+
+		return date, nil
+	}
+}
+
+func (f Field) zero() any {
+	if f.Type == Money {
+		return utils.Money(0)
+	}
+
+	return float64(0)
+}
+
+// scale multiplies n by 10 to the power of exp, rounding when exp is negative
+func scale(n int64, exp int) int64 {
+	for ; exp > 0; exp-- {
+		n *= 10
+	}
+
+	for ; exp < 0; exp++ {
+		n = (n + 5) / 10
+	}
+
+	return n
+}
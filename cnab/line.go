@@ -0,0 +1,136 @@
+package cnab
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidLength is returned for records shorter or longer than the layout
+	ErrInvalidLength = errors.New("invalid record length")
+	// ErrUnexpectedRecord is returned for records out of the order the layout sets
+	ErrUnexpectedRecord = errors.New("unexpected record")
+)
+
+// LineError reports a malformed line of a CNAB file. Column is the 1-based position the
+// problem starts at and Field the name of the field there, when it is known.
+type LineError struct {
+	Line   int
+	Column int
+	Field  string
+	Err    error
+}
+
+func (e *LineError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+	}
+
+	return fmt.Sprintf("line %d, column %d (%s): %v", e.Line, e.Column, e.Field, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Line reads the fields of a record. Like Record, it keeps the first field that fails, as
+// a *LineError returned by Err, and reads zero values after it.
+type Line struct {
+	number int
+	data   string
+	err    error
+}
+
+// NewLine returns a reader of the fields of data, the line number of a file
+func NewLine(number int, data string) *Line {
+	return &Line{number: number, data: data}
+}
+
+// Err returns the first error found while reading the line
+func (l *Line) Err() error {
+	return l.err
+}
+
+// Fail records err for the field at start, unless an earlier field failed
+func (l *Line) Fail(field string, start int, err error) {
+	if l.err == nil {
+		l.err = &LineError{Line: l.number, Column: start, Field: field, Err: err}
+	}
+}
+
+func (l *Line) get(field string, start, end int) (string, bool) {
+	if l.err != nil {
+		return "", false
+	}
+
+	if start < 1 || end > len(l.data) || start > end {
+		l.Fail(field, start, ErrInvalidLength)
+		return "", false
+	}
+
+	return l.data[start-1 : end], true
+}
+
+// Raw returns the positions start to end as they are
+func (l *Line) Raw(field string, start, end int) string {
+	value, _ := l.get(field, start, end)
+	return value
+}
+
+// Alpha returns the positions start to end without the blanks around them
+func (l *Line) Alpha(field string, start, end int) string {
+	return strings.TrimSpace(l.Raw(field, start, end))
+}
+
+// Digits returns the positions start to end, which must be digits
+func (l *Line) Digits(field string, start, end int) string {
+	value, ok := l.get(field, start, end)
+	if ok && utils.OnlyNumbers(value) != value {
+		l.Fail(field, start, fmt.Errorf("%w: %q is not numeric", ErrInvalidValue, value))
+		return ""
+	}
+
+	return value
+}
+
+// Number returns the integer at positions start to end. Blank fields read as zero.
+func (l *Line) Number(field string, start, end int) int64 {
+	value, ok := l.get(field, start, end)
+	if !ok || strings.TrimSpace(value) == "" {
+		return 0
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || number < 0 {
+		l.Fail(field, start, fmt.Errorf("%w: %q is not numeric", ErrInvalidValue, value))
+		return 0
+	}
+
+	return number
+}
+
+// Money returns the amount at positions start to end, with two implied decimal places
+func (l *Line) Money(field string, start, end int) utils.Money {
+	return utils.Money(l.Number(field, start, end))
+}
+
+// Date returns the date written as DDMMAAAA, or DDMMAA in 6-position fields. Blank fields
+// and zeros read as the zero time.
+func (l *Line) Date(field string, start, end int) time.Time {
+	value, ok := l.get(field, start, end)
+	if !ok || strings.Trim(value, "0 ") == "" {
+		return time.Time{}
+	}
+
+	date, err := time.Parse(dateLayout(end-start+1), value)
+	if err != nil {
+		l.Fail(field, start, fmt.Errorf("%w: %q is not a date", ErrInvalidValue, value))
+		return time.Time{}
+	}
+
+	return date
+}
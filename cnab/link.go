@@ -0,0 +1,50 @@
+package cnab
+
+import (
+	"github.com/fonini/go-boleto-utils/generator"
+	"strings"
+)
+
+// Link sets the barcode of a retorno title when freeField rebuilds the free field of its
+// boleto. The cnab240 and cnab400 readers call it with the FreeField hook of the bank.
+func Link(title *Return, freeField func(r *Return) (string, bool)) {
+	if freeField == nil || title.Amount <= 0 {
+		return
+	}
+
+	field, ok := freeField(title)
+	if !ok {
+		return
+	}
+
+	result, err := generator.Generate(generator.Request{
+		BankCode:  title.BankCode,
+		Currency:  generator.RealCurrency,
+		DueDate:   title.DueDate,
+		Amount:    title.Amount,
+		FreeField: field,
+	})
+	if err == nil {
+		title.Barcode = result.Barcode
+	}
+}
+
+// BradescoFreeField rebuilds the free field of a Banco Bradesco (237) boleto: the agency,
+// wallet, 11-digit nosso número, account and a zero
+func BradescoFreeField(r *Return) (string, bool) {
+	agency := strings.TrimLeft(r.Account.Agency, "0")
+	account := strings.TrimLeft(r.Account.Number, "0")
+
+	if len(agency) > 4 || len(account) > 7 || len(r.OurNumber) != 11 || r.Account.Wallet == "" {
+		return "", false
+	}
+
+	record := NewRecord(25)
+	record.Digits("agency", 1, 4, agency)
+	record.Digits("wallet", 5, 6, r.Account.Wallet)
+	record.Digits("our number", 7, 17, r.OurNumber)
+	record.Digits("account", 18, 24, account)
+	record.Digits("zero", 25, 25, "0")
+
+	return record.String(), record.Err() == nil
+}
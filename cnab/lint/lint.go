@@ -0,0 +1,280 @@
+// Package lint checks a CNAB 240 or 400 file before it is sent to the bank and reports every
+// problem it finds with its line and column: records of the wrong length or out of order,
+// batch and sequence numbers out of step, trailer counts and totals that don't match the
+// file, numeric fields holding other characters, invalid dates, and barcodes or nosso
+// números whose check digits are wrong.
+//
+// Files are read with the layouts of the layout package, and records are told apart by their
+// names. A 240-position layout has a "file header", batches made of a "batch header", detail
+// segments and a "batch trailer", and a "file trailer"; a 400-position layout has a "header",
+// details and a "trailer". Layouts of other lengths only get the checks of each line.
+package lint
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/cnab/cnab240"
+	"github.com/fonini/go-boleto-utils/cnab/layout"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownLayout is returned by Check when the first record doesn't tell which layout
+	// the file is in
+	ErrUnknownLayout = errors.New("unknown CNAB layout")
+	// ErrMissingRecord is reported for files without their trailers
+	ErrMissingRecord = errors.New("missing record")
+	// ErrSequence is reported for batch and record numbers out of step
+	ErrSequence = errors.New("out of sequence")
+	// ErrCount is reported for trailer counts that don't match the records of the file
+	ErrCount = errors.New("count doesn't match the file")
+	// ErrTotal is reported for trailer totals that don't match the titles of the batch
+	ErrTotal = errors.New("total doesn't match the titles")
+	// ErrOurNumberDigit is reported for nosso números whose check digit is wrong
+	ErrOurNumberDigit = errors.New("invalid nosso número check digit")
+)
+
+// Report holds the problems found in a file, sorted by line and column. Layout is the name of
+// the layout the file was checked against and Records the number of records it holds.
+type Report struct {
+	Layout      string
+	Records     int
+	Diagnostics []*cnab.LineError
+}
+
+// Valid reports whether no problem was found
+func (r *Report) Valid() bool {
+	return len(r.Diagnostics) == 0
+}
+
+// Err returns the diagnostics joined in one error, or nil if the file is valid
+func (r *Report) Err() error {
+	errs := make([]error, len(r.Diagnostics))
+	for i, diagnostic := range r.Diagnostics {
+		errs[i] = diagnostic
+	}
+
+	return errors.Join(errs...)
+}
+
+// layouts400 maps the bank codes of the bundled CNAB 400 layouts to their names
+var layouts400 = map[string]string{
+	"237": "bradesco",
+	"341": "itau",
+	"748": "sicredi",
+}
+
+// Check checks the file in r against the layout its first record matches: the CNAB 240
+// remessa or retorno, by the file code at position 143, of the cnab240 variant of the bank
+// at positions 1 to 3, or the CNAB 400 remessa or retorno of the bank at positions 77 to 79.
+// Use CheckLayout for other layouts.
+func Check(r io.Reader) (*Report, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := detect(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	return check(lines, l), nil
+}
+
+// CheckLayout checks the file in r against l, and returns layout.ErrInvalidLayout when l
+// isn't valid
+func CheckLayout(r io.Reader, l *layout.Layout) (*Report, error) {
+	l, err := l.Checked()
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return check(lines, l), nil
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024), 64*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+
+	return lines, scanner.Err()
+}
+
+func detect(lines []string) (*layout.Layout, error) {
+	var first string
+	for _, line := range lines {
+		if line != "" {
+			first = line
+			break
+		}
+	}
+
+	var name string
+
+	switch len(first) {
+	case 240:
+		bank, _ := cnab240.Lookup(first[:3])
+
+		switch first[142] {
+		case '1':
+			name = bank.Remessa.Name
+		case '2':
+			name = bank.Retorno.Name
+		}
+	case 400:
+		bank, ok := layouts400[first[76:79]]
+
+		switch {
+		case !ok:
+		case first[1] == '1':
+			name = bank + "-400-remessa"
+		case first[1] == '2':
+			name = bank + "-400-retorno"
+		}
+	}
+
+	l, ok := layout.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: the first record matches no bundled layout", ErrUnknownLayout)
+	}
+
+	return l, nil
+}
+
+// entry is a record of the file that matched a record of the layout
+type entry struct {
+	line   int
+	data   string
+	record *layout.Record
+	values layout.Values
+}
+
+// structure checks the order of the records of a file and the counts of its trailers
+type structure interface {
+	// next checks a record; e is nil for lines that matched no record of the layout
+	next(c *checker, line int, e *entry)
+	// end checks the file after its last record
+	end(c *checker, line int)
+}
+
+type checker struct {
+	layout    *layout.Layout
+	report    *Report
+	structure structure
+	bankCode  string
+}
+
+func check(lines []string, l *layout.Layout) *Report {
+	c := &checker{layout: l, report: &Report{Layout: l.Name}}
+
+	switch l.Length {
+	case 240:
+		c.structure = &batches{}
+	case 400:
+		c.structure = &sequence{}
+	}
+
+	last := 0
+	for i, text := range lines {
+		if text == "" {
+			continue
+		}
+
+		last = i + 1
+		c.report.Records++
+
+		e := c.read(last, text)
+		if e != nil {
+			c.fields(e)
+		}
+
+		if c.structure != nil {
+			c.structure.next(c, last, e)
+		}
+	}
+
+	if c.structure != nil {
+		c.structure.end(c, last)
+	}
+
+	sort.SliceStable(c.report.Diagnostics, func(i, j int) bool {
+		a, b := c.report.Diagnostics[i], c.report.Diagnostics[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	return c.report
+}
+
+// read matches text with a record of the layout and reads its fields, reporting the fields
+// that can't be read
+func (c *checker) read(line int, text string) *entry {
+	if len(text) != c.layout.Length {
+		c.fail(line, 1, "", fmt.Errorf("%w: %d positions, want %d", cnab.ErrInvalidLength, len(text), c.layout.Length))
+		return nil
+	}
+
+	record, ok := c.layout.Match(text)
+	if !ok {
+		c.fail(line, 1, "", fmt.Errorf("%w: no record of %s matches", layout.ErrUnknownRecord, c.layout.Name))
+		return nil
+	}
+
+	values, errs := record.Check(text)
+	for _, err := range errs {
+		if fieldErr, ok := err.(*cnab.FieldError); ok {
+			c.fail(line, fieldErr.Start, fieldErr.Field, fmt.Errorf("%w: %q", fieldErr.Err, fieldErr.Value))
+		}
+	}
+
+	if c.bankCode == "" {
+		c.bankCode, _ = values["bank code"].(string)
+	}
+
+	return &entry{line: line, data: text, record: record, values: values}
+}
+
+func (c *checker) fail(line, column int, field string, err error) {
+	c.report.Diagnostics = append(c.report.Diagnostics, &cnab.LineError{Line: line, Column: column, Field: field, Err: err})
+}
+
+// failField reports err for the field of e called name
+func (c *checker) failField(e *entry, name string, err error) {
+	field, _ := e.record.Field(name)
+	c.fail(e.line, max(field.Start, 1), name, err)
+}
+
+// number returns the value of the Numeric field of e called name, and false when the record
+// has no such field or it couldn't be read
+func (e *entry) number(name string) (int, bool) {
+	text, ok := e.values[name].(string)
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, text == ""
+	}
+
+	return n, true
+}
+
+func (e *entry) text(name string) string {
+	text, _ := e.values[name].(string)
+	return text
+}
@@ -0,0 +1,274 @@
+package lint
+
+import (
+	"errors"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/cnab/cnab240"
+	"github.com/fonini/go-boleto-utils/cnab/cnab400"
+	"github.com/fonini/go-boleto-utils/cnab/internal/cnabtest"
+	"github.com/fonini/go-boleto-utils/cnab/layout"
+	"github.com/fonini/go-boleto-utils/utils"
+	"github.com/fonini/go-boleto-utils/validator"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type diagnostic struct {
+	Line   int
+	Column int
+	Field  string
+}
+
+func remittance(bankCode string) *cnab.Remittance {
+	title := func(ourNumber, digit string, amount int) cnab.Title {
+		return cnab.Title{
+			OurNumber:      ourNumber,
+			OurNumberDigit: digit,
+			DocumentNumber: "NF-" + ourNumber,
+			DueDate:        time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC),
+			Amount:         utils.Money(116037 * amount),
+			Kind:           cnab.KindDuplicataServico,
+			Payer:          cnabtest.Payer(),
+		}
+	}
+
+	return cnabtest.Remittance(bankCode,
+		cnab.Account{Agency: "4321", Number: "98765", Digit: "4", Agreement: "12345", Wallet: "09"},
+		title("12345678", "8", 1), title("12345679", "6", 2),
+	)
+}
+
+// set returns line with value at the 1-based position start
+func set(line string, start int, value string) string {
+	return line[:start-1] + value + line[start-1+len(value):]
+}
+
+func diagnostics(report *Report) []diagnostic {
+	var got []diagnostic
+	for _, d := range report.Diagnostics {
+		got = append(got, diagnostic{d.Line, d.Column, d.Field})
+	}
+
+	return got
+}
+
+func TestValues_Check(t *testing.T) {
+	cnab240File := func(t *testing.T) []string {
+		return cnabtest.Lines(t, cnab240.Write, remittance("237"), cnab240.RecordLength)
+	}
+
+	cnab400File := func(bankCode string) func(t *testing.T) []string {
+		return func(t *testing.T) []string {
+			return cnabtest.Lines(t, cnab400.Write, remittance(bankCode), cnab400.RecordLength)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		file   func(t *testing.T) []string
+		edit   func(lines []string) []string
+		layout string
+		want   []diagnostic
+		errs   []error
+	}{
+		{
+			name:   "valid CNAB 240",
+			file:   cnab240File,
+			layout: "bradesco-240-remessa",
+		},
+		{
+			name: "CNAB 240 amount out of the total",
+			file: cnab240File,
+			edit: func(lines []string) []string {
+				lines[2] = set(lines[2], 86, "000000000116038")
+				return lines
+			},
+			layout: "bradesco-240-remessa",
+			want:   []diagnostic{{7, 30, "simple total"}},
+			errs:   []error{ErrTotal},
+		},
+		{
+			name: "CNAB 240 segment numbers",
+			file: cnab240File,
+			edit: func(lines []string) []string {
+				lines[3] = set(lines[3], 9, "00003")
+				lines[4] = set(lines[4], 4, "0002")
+				return lines
+			},
+			layout: "bradesco-240-remessa",
+			want:   []diagnostic{{4, 9, "sequence"}, {5, 4, "batch"}},
+			errs:   []error{ErrSequence, ErrSequence},
+		},
+		{
+			name: "CNAB 240 fields",
+			file: cnab240File,
+			edit: func(lines []string) []string {
+				lines[2] = set(lines[2], 78, "31022024")
+				lines[4] = set(lines[4], 86, "0000000001160A4")
+				lines[5] = lines[5][:239]
+				return lines
+			},
+			layout: "bradesco-240-remessa",
+			want:   []diagnostic{{3, 78, "due date"}, {5, 86, "amount"}, {6, 1, ""}},
+			errs:   []error{cnab.ErrInvalidValue, cnab.ErrInvalidValue, cnab.ErrInvalidLength},
+		},
+		{
+			name: "CNAB 240 without batch trailer",
+			file: cnab240File,
+			edit: func(lines []string) []string {
+				return append(lines[:6], lines[7])
+			},
+			layout: "bradesco-240-remessa",
+			want:   []diagnostic{{7, 1, ""}, {7, 24, "records"}},
+			errs:   []error{cnab.ErrUnexpectedRecord, ErrCount},
+		},
+		{
+			name: "CNAB 240 without file trailer",
+			file: cnab240File,
+			edit: func(lines []string) []string {
+				return lines[:7]
+			},
+			layout: "bradesco-240-remessa",
+			want:   []diagnostic{{7, 1, ""}},
+			errs:   []error{ErrMissingRecord},
+		},
+		{
+			name: "CNAB 240 nosso número digit",
+			file: cnab240File,
+			edit: func(lines []string) []string {
+				lines[4] = set(lines[4], 57, "7")
+				return lines
+			},
+			layout: "bradesco-240-remessa",
+			want:   []diagnostic{{5, 57, "our number digit"}},
+			errs:   []error{ErrOurNumberDigit},
+		},
+		{
+			name:   "valid Bradesco CNAB 400",
+			file:   cnab400File("237"),
+			layout: "bradesco-400-remessa",
+		},
+		{
+			name:   "valid Itaú CNAB 400",
+			file:   cnab400File("341"),
+			layout: "itau-400-remessa",
+		},
+		{
+			name:   "valid Sicredi CNAB 400",
+			file:   cnab400File("748"),
+			layout: "sicredi-400-remessa",
+		},
+		{
+			name: "CNAB 400 order",
+			file: cnab400File("237"),
+			edit: func(lines []string) []string {
+				lines[2] = set(lines[2], 395, "000002")
+				return append(lines[:3], lines[1], lines[0])
+			},
+			layout: "bradesco-400-remessa",
+			want:   []diagnostic{{3, 395, "record sequence"}, {4, 395, "record sequence"}, {5, 1, ""}, {5, 1, ""}, {5, 395, "record sequence"}},
+			errs:   []error{ErrSequence, ErrSequence, cnab.ErrUnexpectedRecord, ErrMissingRecord, ErrSequence},
+		},
+		{
+			name: "CNAB 400 nosso número digit",
+			file: cnab400File("237"),
+			edit: func(lines []string) []string {
+				lines[2] = set(lines[2], 82, "P")
+				return lines
+			},
+			layout: "bradesco-400-remessa",
+			want:   []diagnostic{{3, 82, "our number digit"}},
+			errs:   []error{ErrOurNumberDigit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := tt.file(t)
+			if tt.edit != nil {
+				lines = tt.edit(lines)
+			}
+
+			report, err := Check(strings.NewReader(strings.Join(lines, "\r\n") + "\r\n"))
+			if err != nil {
+				t.Fatalf("Check returned %v", err)
+			}
+
+			if report.Layout != tt.layout || report.Records != len(lines) {
+				t.Errorf("Check read %d records of %s, want %d of %s", report.Records, report.Layout, len(lines), tt.layout)
+			}
+
+			if diff := cmp.Diff(tt.want, diagnostics(report)); diff != "" {
+				t.Fatalf("diagnostics mismatch (-want +got):\n%s\n%v", diff, report.Err())
+			}
+
+			for i, err := range tt.errs {
+				if !errors.Is(report.Diagnostics[i], err) {
+					t.Errorf("diagnostic %d is %v, want %v", i, report.Diagnostics[i], err)
+				}
+			}
+
+			if report.Valid() != (len(tt.want) == 0) || (report.Err() == nil) != report.Valid() {
+				t.Errorf("Valid() = %v, Err() = %v", report.Valid(), report.Err())
+			}
+		})
+	}
+}
+
+func TestValues_CheckLayout(t *testing.T) {
+	l, err := layout.Load(strings.NewReader(`{
+	  "name": "payments-60",
+	  "length": 60,
+	  "records": [{
+	    "name": "payment",
+	    "fields": [
+	      {"name": "barcode", "start": 1, "length": 44, "type": "numeric"},
+	      {"name": "payment date", "start": 45, "length": 8, "type": "date"}
+	    ]
+	  }]
+	}`))
+	if err != nil {
+		t.Fatalf("Load returned %v", err)
+	}
+
+	barcode := "23791990600001160374321090000012345600987650"
+	payments := barcode + "20112024" + strings.Repeat(" ", 8) + "\n" +
+		set(barcode, 5, "8") + "20112024" + strings.Repeat(" ", 8) + "\n" +
+		barcode + "2011202X" + strings.Repeat(" ", 8) + "\n"
+
+	report, err := CheckLayout(strings.NewReader(payments), l)
+	if err != nil {
+		t.Fatalf("CheckLayout returned %v", err)
+	}
+
+	want := []diagnostic{{2, 5, "barcode"}, {3, 45, "payment date"}}
+	if diff := cmp.Diff(want, diagnostics(report)); diff != "" {
+		t.Fatalf("diagnostics mismatch (-want +got):\n%s", diff)
+	}
+
+	if !errors.Is(report.Diagnostics[0], validator.ErrGeneralCheckDigit) {
+		t.Errorf("diagnostic 0 is %v, want ErrGeneralCheckDigit", report.Diagnostics[0])
+	}
+
+	remessa, _ := layout.Lookup("febraban-240-remessa")
+	lines := cnabtest.Lines(t, cnab240.Write, remittance("237"), cnab240.RecordLength)
+
+	report, err = CheckLayout(strings.NewReader(strings.Join(lines[1:], "\n")), remessa)
+	if err != nil {
+		t.Fatalf("CheckLayout returned %v", err)
+	}
+
+	want = []diagnostic{{1, 1, ""}, {7, 24, "records"}}
+	if diff := cmp.Diff(want, diagnostics(report)); diff != "" {
+		t.Errorf("diagnostics mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, file := range []string{"", strings.Repeat("0", 240) + "\n", strings.Repeat("0", 400)} {
+		if _, err := Check(strings.NewReader(file)); !errors.Is(err, ErrUnknownLayout) {
+			t.Errorf("Check(%.10q) returned %v, want ErrUnknownLayout", file, err)
+		}
+	}
+}
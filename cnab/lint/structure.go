@@ -0,0 +1,187 @@
+package lint
+
+import (
+	"fmt"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/utils"
+)
+
+// batches checks a CNAB 240 file: a file header, batches numbered from 1 whose segments are
+// numbered from 1 and whose trailers count their records, titles and amounts, and a file
+// trailer counting the batches and records of the file
+type batches struct {
+	records  int
+	batches  int
+	open     bool
+	ended    bool
+	sequence int
+	batch    batchTotals
+}
+
+// batchTotals are what a batch trailer counts: its records, counting its header and
+// trailer, and the titles and amount of its P or T segments. Unread is set when the amount
+// of a title couldn't be read, so the total isn't known.
+type batchTotals struct {
+	records int
+	titles  int
+	total   utils.Money
+	unread  bool
+}
+
+func (b *batches) next(c *checker, line int, e *entry) {
+	b.records++
+	if b.open {
+		b.batch.records++
+	}
+
+	// lines that match no record still take a place in the batch
+	if e == nil {
+		if b.open {
+			b.sequence++
+		}
+		return
+	}
+
+	if b.ended {
+		c.fail(line, 1, "", fmt.Errorf("%w: %s after the file trailer", cnab.ErrUnexpectedRecord, e.record.Name))
+		return
+	}
+
+	if b.records == 1 && e.record.Name != "file header" {
+		c.fail(line, 1, "", fmt.Errorf("%w: the file starts with a %s, want a file header", cnab.ErrUnexpectedRecord, e.record.Name))
+	}
+
+	switch e.record.Name {
+	case "file header":
+		if b.records > 1 {
+			c.fail(line, 1, "", fmt.Errorf("%w: file header after the first record", cnab.ErrUnexpectedRecord))
+		}
+
+		b.checkBatch(c, e, 0)
+	case "batch header":
+		if b.open {
+			c.fail(line, 1, "", fmt.Errorf("%w: batch %d has no trailer", cnab.ErrUnexpectedRecord, b.batches))
+		}
+
+		b.batches++
+		b.open = true
+		b.sequence = 0
+		b.batch = batchTotals{records: 1}
+		b.checkBatch(c, e, b.batches)
+	case "batch trailer":
+		if !b.open {
+			c.fail(line, 1, "", fmt.Errorf("%w: batch trailer outside a batch", cnab.ErrUnexpectedRecord))
+			return
+		}
+
+		b.open = false
+		b.checkBatch(c, e, b.batches)
+		b.checkTrailer(c, e)
+	case "file trailer":
+		if b.open {
+			c.fail(line, 1, "", fmt.Errorf("%w: batch %d has no trailer", cnab.ErrUnexpectedRecord, b.batches))
+			b.open = false
+		}
+
+		b.ended = true
+		b.checkBatch(c, e, 9999)
+
+		if batches, ok := e.number("batches"); ok && batches != b.batches {
+			c.failField(e, "batches", fmt.Errorf("%w: %d batches, want %d", ErrCount, batches, b.batches))
+		}
+
+		if records, ok := e.number("records"); ok && records != b.records {
+			c.failField(e, "records", fmt.Errorf("%w: %d records, want %d", ErrCount, records, b.records))
+		}
+	default:
+		if !b.open {
+			c.fail(line, 1, "", fmt.Errorf("%w: %s outside a batch", cnab.ErrUnexpectedRecord, e.record.Name))
+			return
+		}
+
+		b.sequence++
+		b.checkBatch(c, e, b.batches)
+
+		if sequence, ok := e.number("sequence"); ok && sequence != b.sequence {
+			c.failField(e, "sequence", fmt.Errorf("%w: record %d, want %d", ErrSequence, sequence, b.sequence))
+		}
+
+		if _, ok := e.record.Field("amount"); ok {
+			amount, ok := e.values["amount"].(utils.Money)
+			b.batch.titles++
+			b.batch.total += amount
+			b.batch.unread = b.batch.unread || !ok
+		}
+	}
+}
+
+// checkBatch checks the batch number of a record: 0 in the file header, 9999 in the file
+// trailer and the number of the batch in the others
+func (b *batches) checkBatch(c *checker, e *entry, want int) {
+	if batch, ok := e.number("batch"); ok && batch != want {
+		c.failField(e, "batch", fmt.Errorf("%w: batch %d, want %d", ErrSequence, batch, want))
+	}
+}
+
+// checkTrailer checks the counts of a batch trailer. Banks that leave the titles blank in
+// their retornos don't get the titles and their total checked.
+func (b *batches) checkTrailer(c *checker, e *entry) {
+	if records, ok := e.number("records"); ok && records != b.batch.records {
+		c.failField(e, "records", fmt.Errorf("%w: %d records, want %d", ErrCount, records, b.batch.records))
+	}
+
+	titles, ok := e.number("simple titles")
+	if !ok || titles == 0 {
+		return
+	}
+
+	if titles != b.batch.titles {
+		c.failField(e, "simple titles", fmt.Errorf("%w: %d titles, want %d", ErrCount, titles, b.batch.titles))
+	}
+
+	if total, ok := e.values["simple total"].(utils.Money); ok && !b.batch.unread && total != b.batch.total {
+		c.failField(e, "simple total", fmt.Errorf("%w: %s, want %s", ErrTotal, total, b.batch.total))
+	}
+}
+
+func (b *batches) end(c *checker, line int) {
+	if !b.ended {
+		c.fail(line, 1, "", fmt.Errorf("%w: the file has no file trailer", ErrMissingRecord))
+	}
+}
+
+// sequence checks a CNAB 400 file: a header, details and a trailer, each holding its
+// position in the file as its record sequence
+type sequence struct {
+	records int
+	ended   bool
+}
+
+func (s *sequence) next(c *checker, line int, e *entry) {
+	s.records++
+
+	if e == nil {
+		return
+	}
+
+	if recordSequence, ok := e.number("record sequence"); ok && recordSequence != s.records {
+		c.failField(e, "record sequence", fmt.Errorf("%w: record %d, want %d", ErrSequence, recordSequence, s.records))
+	}
+
+	switch {
+	case s.ended:
+		c.fail(line, 1, "", fmt.Errorf("%w: %s after the trailer", cnab.ErrUnexpectedRecord, e.record.Name))
+	case s.records == 1 && e.record.Name != "header":
+		c.fail(line, 1, "", fmt.Errorf("%w: the file starts with a %s, want a header", cnab.ErrUnexpectedRecord, e.record.Name))
+	case s.records > 1 && e.record.Name == "header":
+		c.fail(line, 1, "", fmt.Errorf("%w: header after the first record", cnab.ErrUnexpectedRecord))
+	case e.record.Name == "trailer":
+		s.ended = true
+	}
+}
+
+func (s *sequence) end(c *checker, line int) {
+	if !s.ended {
+		c.fail(line, 1, "", fmt.Errorf("%w: the file has no trailer", ErrMissingRecord))
+	}
+}
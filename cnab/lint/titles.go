@@ -0,0 +1,157 @@
+package lint
+
+import (
+	"fmt"
+	"github.com/fonini/go-boleto-utils/cnab"
+	"github.com/fonini/go-boleto-utils/cnab/cnab400"
+	"github.com/fonini/go-boleto-utils/utils"
+	"github.com/fonini/go-boleto-utils/validator"
+	"strconv"
+)
+
+// codeFields are the fields whose values are run through validator.ValidateDetailed
+var codeFields = []string{"barcode", "digitable line"}
+
+// ourNumberDigits returns the nosso número check digit of a title, for the banks whose rule
+// is known, and false when the title lacks a part of it
+var ourNumberDigits = map[string]func(r *cnab.Return) (string, bool){
+	"237": bradescoDigit,
+	"341": itauDigit,
+}
+
+// fields checks the barcodes and the nosso número of a record
+func (c *checker) fields(e *entry) {
+	for _, name := range codeFields {
+		if code := e.text(name); code != "" {
+			c.checkCode(e, name, code)
+		}
+	}
+
+	if _, ok := e.record.Field("our number"); ok && e.text("our number") != "" {
+		c.checkOurNumber(e)
+	}
+}
+
+// checkCode reports the first check a barcode or digitable line fails, at the position of
+// the failing digits
+func (c *checker) checkCode(e *entry, name string, code string) {
+	result := validator.ValidateDetailed(code)
+
+	err := result.Err()
+	if err == nil {
+		return
+	}
+
+	field, _ := e.record.Field(name)
+	column := field.Start
+
+	for _, failure := range result.Failures() {
+		if failure.Field != validator.FieldBank {
+			if failure.Offset >= 0 {
+				column += failure.Offset
+			}
+			break
+		}
+	}
+
+	c.fail(e.line, column, name, fmt.Errorf("%w: %q", err, code))
+}
+
+// checkOurNumber rebuilds the title of a detail and checks the nosso número check digit
+func (c *checker) checkOurNumber(e *entry) {
+	digit, ok := ourNumberDigits[c.bankCode]
+	if !ok {
+		return
+	}
+
+	title := c.title(e)
+	if title.OurNumberDigit == "" {
+		return
+	}
+
+	want, ok := digit(title)
+	if !ok || want == title.OurNumberDigit {
+		return
+	}
+
+	name := "our number digit"
+	if _, ok := e.record.Field(name); !ok {
+		name = "our number"
+	}
+
+	c.failField(e, name, fmt.Errorf("%w: %s-%s, want digit %s", ErrOurNumberDigit, title.OurNumber, title.OurNumberDigit, want))
+}
+
+// title reads the account and nosso número of a detail as the cnab240 and cnab400 readers do
+func (c *checker) title(e *entry) *cnab.Return {
+	title := &cnab.Return{
+		BankCode: c.bankCode,
+		Account: cnab.Account{
+			Agency: e.text("agency"),
+			Number: e.text("account"),
+			Digit:  e.text("account digit"),
+			Wallet: e.text("wallet"),
+		},
+		OurNumber:      e.text("our number"),
+		OurNumberDigit: e.text("our number digit"),
+	}
+
+	if c.layout.Length == 400 {
+		if bank, ok := cnab400.Lookup(c.bankCode); ok && bank.ParseReturn != nil {
+			bank.ParseReturn(cnab400.Values(e.values), title)
+		}
+	}
+
+	return title
+}
+
+// bradescoDigit computes the module 11 digit of the wallet and nosso número with weights 2
+// to 7, where remainder 1 maps to P and 0 to 0
+func bradescoDigit(r *cnab.Return) (string, bool) {
+	block := r.Account.Wallet + r.OurNumber
+	if len(r.Account.Wallet) != 2 || len(r.OurNumber) != 11 || utils.OnlyNumbers(block) != block {
+		return "", false
+	}
+
+	sum := 0
+	weight := 2
+
+	for i := len(block) - 1; i >= 0; i-- {
+		sum += int(block[i]-'0') * weight
+
+		weight++
+		if weight > 7 {
+			weight = 2
+		}
+	}
+
+	switch remainder := sum % 11; remainder {
+	case 0:
+		return "0", true
+	case 1:
+		return "P", true
+	default:
+		return strconv.Itoa(11 - remainder), true
+	}
+}
+
+// itauDigit computes the module 10 DAC of the agency, account, wallet and nosso número, or
+// of the wallet and nosso número alone for the wallets that leave the account out
+func itauDigit(r *cnab.Return) (string, bool) {
+	block := r.Account.Agency + r.Account.Number + r.Account.Wallet + r.OurNumber
+
+	switch r.Account.Wallet {
+	case "126", "131", "146", "150", "168":
+		block = r.Account.Wallet + r.OurNumber
+	default:
+		if len(r.Account.Agency) != 4 || len(r.Account.Number) != 5 {
+			return "", false
+		}
+	}
+
+	if len(r.Account.Wallet) != 3 || len(r.OurNumber) != 8 || utils.OnlyNumbers(block) != block {
+		return "", false
+	}
+
+	return utils.CalculateVerificationDigit(block), true
+}
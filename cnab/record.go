@@ -0,0 +1,181 @@
+package cnab
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/utils"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrFieldOverflow is returned when a value does not fit its field
+	ErrFieldOverflow = errors.New("value does not fit the field")
+	// ErrInvalidValue is returned when a value can't be written to or read from a field
+	ErrInvalidValue = errors.New("invalid field value")
+)
+
+// FieldError reports the field of a record that could not be written or read. Start and
+// End are 1-based and inclusive, as in the bank manuals.
+type FieldError struct {
+	Field string
+	Start int
+	End   int
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s (%d-%d): %v: %q", e.Field, e.Start, e.End, e.Err, e.Value)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Record builds a fixed-width record. Fields are addressed by their 1-based, inclusive
+// positions; the first field that fails is kept and returned by Err, so a record can be
+// filled without checking every call.
+type Record struct {
+	data []byte
+	err  error
+}
+
+// NewRecord returns a record of length blanks
+func NewRecord(length int) *Record {
+	return &Record{data: []byte(strings.Repeat(" ", length))}
+}
+
+// Err returns the first error found while filling the record
+func (r *Record) Err() error {
+	return r.err
+}
+
+// String returns the record
+func (r *Record) String() string {
+	return string(r.data)
+}
+
+func (r *Record) fail(field string, start, end int, value string, err error) {
+	if r.err == nil {
+		r.err = &FieldError{Field: field, Start: start, End: end, Value: value, Err: err}
+	}
+}
+
+func (r *Record) set(field string, start, end int, value string) {
+	if start < 1 || end > len(r.data) || start > end {
+		r.fail(field, start, end, value, ErrFieldOverflow)
+		return
+	}
+
+	copy(r.data[start-1:end], value)
+}
+
+// Alpha writes value left-aligned and padded with blanks, in upper case and without
+// accents, as banks expect. Values longer than the field are truncated.
+func (r *Record) Alpha(field string, start, end int, value string) {
+	value = Normalize(value)
+
+	if length := end - start + 1; len(value) > length {
+		value = value[:length]
+	} else {
+		value += strings.Repeat(" ", length-len(value))
+	}
+
+	r.set(field, start, end, value)
+}
+
+// Digits writes a string of digits right-aligned and padded with zeros
+func (r *Record) Digits(field string, start, end int, value string) {
+	length := end - start + 1
+
+	switch {
+	case utils.OnlyNumbers(value) != value:
+		r.fail(field, start, end, value, ErrInvalidValue)
+	case len(value) > length:
+		r.fail(field, start, end, value, ErrFieldOverflow)
+	default:
+		r.set(field, start, end, strings.Repeat("0", length-len(value))+value)
+	}
+}
+
+// Number writes a non-negative integer right-aligned and padded with zeros
+func (r *Record) Number(field string, start, end int, value int64) {
+	if value < 0 {
+		r.fail(field, start, end, strconv.FormatInt(value, 10), ErrInvalidValue)
+		return
+	}
+
+	r.Digits(field, start, end, strconv.FormatInt(value, 10))
+}
+
+// Money writes an amount in centavos, with two implied decimal places
+func (r *Record) Money(field string, start, end int, value utils.Money) {
+	r.Number(field, start, end, int64(value))
+}
+
+// Rate writes a rate with the given number of implied decimal places
+func (r *Record) Rate(field string, start, end int, value float64, decimals int) {
+	r.Number(field, start, end, int64(math.Round(value*math.Pow10(decimals))))
+}
+
+// Date writes a date as DDMMAAAA, or DDMMAA in 6-position fields. A zero date is written as
+// zeros.
+func (r *Record) Date(field string, start, end int, value time.Time) {
+	layout := dateLayout(end - start + 1)
+
+	if value.IsZero() {
+		r.set(field, start, end, strings.Repeat("0", end-start+1))
+		return
+	}
+
+	r.set(field, start, end, value.Format(layout))
+}
+
+// Time writes the time of day of value as HHMMSS in a 6-position field
+func (r *Record) Time(field string, start, end int, value time.Time) {
+	text := value.Format("150405")
+
+	switch length := end - start + 1; {
+	case length < len(text):
+		r.fail(field, start, end, text, ErrFieldOverflow)
+	case length > len(text):
+		r.fail(field, start, end, text, ErrInvalidValue)
+	default:
+		r.set(field, start, end, text)
+	}
+}
+
+func dateLayout(length int) string {
+	if length == 6 {
+		return "020106"
+	}
+
+	return "02012006"
+}
+
+var accents = strings.NewReplacer(
+	"Á", "A", "À", "A", "Â", "A", "Ã", "A", "Ä", "A",
+	"É", "E", "È", "E", "Ê", "E", "Ë", "E",
+	"Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+	"Ó", "O", "Ò", "O", "Ô", "O", "Õ", "O", "Ö", "O",
+	"Ú", "U", "Ù", "U", "Û", "U", "Ü", "U",
+	"Ç", "C", "Ñ", "N", "º", "O", "ª", "A",
+)
+
+// Normalize converts value to the character set of CNAB files: upper case ASCII letters,
+// digits and punctuation. Accents are dropped and other characters become blanks.
+func Normalize(value string) string {
+	value = accents.Replace(strings.ToUpper(value))
+
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return ' '
+		}
+
+		return r
+	}, value)
+}
@@ -0,0 +1,196 @@
+package freefield
+
+import (
+	"github.com/fonini/go-boleto-utils/utils"
+	"strings"
+)
+
+// BancoDoBrasil decodes Banco do Brasil (001) free fields. AgreementLength selects the
+// convênio layout (4, 6 or 7 digits); when zero it is detected: 7 when the field starts
+// with six zeros, 6 otherwise. Six-digit convênios ending in service code "21" carry a
+// 17-digit nosso número and no agency or account.
+type BancoDoBrasil struct {
+	AgreementLength int
+}
+
+// Decode decodes a Banco do Brasil free field
+func (d BancoDoBrasil) Decode(freeField string) (*utils.FreeField, error) {
+	if !isFreeField(freeField) {
+		return nil, ErrInvalidFreeField
+	}
+
+	agreementLength := d.AgreementLength
+	if agreementLength == 0 {
+		agreementLength = 6
+		if strings.HasPrefix(freeField, "000000") {
+			agreementLength = 7
+		}
+	}
+
+	switch agreementLength {
+	case 4:
+		return &utils.FreeField{
+			Agreement: freeField[0:4],
+			OurNumber: freeField[0:11],
+			Agency:    freeField[11:15],
+			Account:   freeField[15:23],
+			Wallet:    freeField[23:25],
+		}, nil
+	case 6:
+		if freeField[23:25] == "21" {
+			return &utils.FreeField{
+				Agreement: freeField[0:6],
+				OurNumber: freeField[6:23],
+				Extra:     map[string]string{"Service": freeField[23:25]},
+			}, nil
+		}
+
+		return &utils.FreeField{
+			Agreement: freeField[0:6],
+			OurNumber: freeField[0:11],
+			Agency:    freeField[11:15],
+			Account:   freeField[15:23],
+			Wallet:    freeField[23:25],
+		}, nil
+	case 7:
+		return &utils.FreeField{
+			Agreement: freeField[6:13],
+			OurNumber: freeField[6:23],
+			Wallet:    freeField[23:25],
+		}, nil
+	default:
+		return nil, ErrInvalidFreeField
+	}
+}
+
+// Santander decodes Banco Santander (033) free fields: the fixed digit 9, beneficiary code,
+// nosso número with its check digit, IOF rate (Extra "IOF") and wallet.
+type Santander struct{}
+
+// Decode decodes a Santander free field
+func (Santander) Decode(freeField string) (*utils.FreeField, error) {
+	if !isFreeField(freeField) {
+		return nil, ErrInvalidFreeField
+	}
+
+	return &utils.FreeField{
+		Agreement:      freeField[1:8],
+		OurNumber:      freeField[8:20],
+		OurNumberDigit: freeField[20:21],
+		Wallet:         freeField[22:25],
+		Extra:          map[string]string{"IOF": freeField[21:22]},
+	}, nil
+}
+
+// Caixa decodes Caixa Econômica Federal (104) SIGCB free fields. The 17-digit nosso número is
+// rebuilt from its three sequences and two constants: Wallet holds the first constant
+// (1 registered, 2 unregistered) and Extra "Issuer" the second (4 issued by the beneficiary).
+// Extra "AgreementDigit" and "FreeFieldDigit" hold the check digits.
+type Caixa struct{}
+
+// Decode decodes a Caixa SIGCB free field
+func (Caixa) Decode(freeField string) (*utils.FreeField, error) {
+	if !isFreeField(freeField) {
+		return nil, ErrInvalidFreeField
+	}
+
+	return &utils.FreeField{
+		Agreement: freeField[0:6],
+		Wallet:    freeField[10:11],
+		OurNumber: freeField[10:11] + freeField[14:15] + freeField[7:10] + freeField[11:14] + freeField[15:24],
+		Extra: map[string]string{
+			"AgreementDigit": freeField[6:7],
+			"Issuer":         freeField[14:15],
+			"FreeFieldDigit": freeField[24:25],
+		},
+	}, nil
+}
+
+// Bradesco decodes Banco Bradesco (237) free fields: agency, wallet, nosso número and account.
+type Bradesco struct{}
+
+// Decode decodes a Bradesco free field
+func (Bradesco) Decode(freeField string) (*utils.FreeField, error) {
+	if !isFreeField(freeField) {
+		return nil, ErrInvalidFreeField
+	}
+
+	return &utils.FreeField{
+		Agency:    freeField[0:4],
+		Wallet:    freeField[4:6],
+		OurNumber: freeField[6:17],
+		Account:   freeField[17:24],
+	}, nil
+}
+
+// Itau decodes Itaú Unibanco (341) free fields: wallet, nosso número and its DAC, agency,
+// account and its DAC.
+type Itau struct{}
+
+// Decode decodes an Itaú free field
+func (Itau) Decode(freeField string) (*utils.FreeField, error) {
+	if !isFreeField(freeField) {
+		return nil, ErrInvalidFreeField
+	}
+
+	return &utils.FreeField{
+		Wallet:         freeField[0:3],
+		OurNumber:      freeField[3:11],
+		OurNumberDigit: freeField[11:12],
+		Agency:         freeField[12:16],
+		Account:        freeField[16:21],
+		AccountDigit:   freeField[21:22],
+	}, nil
+}
+
+// Sicredi decodes Banco Cooperativo Sicredi (748) free fields: collection type (Extra
+// "CollectionType"), wallet, nosso número, cooperative (Agency), post (Extra "Post"),
+// beneficiary code and the free field check digit (Extra "FreeFieldDigit").
+type Sicredi struct{}
+
+// Decode decodes a Sicredi free field
+func (Sicredi) Decode(freeField string) (*utils.FreeField, error) {
+	if !isFreeField(freeField) {
+		return nil, ErrInvalidFreeField
+	}
+
+	return &utils.FreeField{
+		Wallet:         freeField[1:2],
+		OurNumber:      freeField[2:10],
+		OurNumberDigit: freeField[10:11],
+		Agency:         freeField[11:15],
+		Agreement:      freeField[17:22],
+		Extra: map[string]string{
+			"CollectionType": freeField[0:1],
+			"Post":           freeField[15:17],
+			"FreeFieldDigit": freeField[24:25],
+		},
+	}, nil
+}
+
+// Sicoob decodes Sicoob / Bancoob (756) free fields: wallet, cooperative (Agency), modality
+// (Extra "Modality"), beneficiary code, nosso número and installment (Extra "Installment").
+type Sicoob struct{}
+
+// Decode decodes a Sicoob free field
+func (Sicoob) Decode(freeField string) (*utils.FreeField, error) {
+	if !isFreeField(freeField) {
+		return nil, ErrInvalidFreeField
+	}
+
+	return &utils.FreeField{
+		Wallet:         freeField[0:1],
+		Agency:         freeField[1:5],
+		Agreement:      freeField[7:14],
+		OurNumber:      freeField[14:21],
+		OurNumberDigit: freeField[21:22],
+		Extra: map[string]string{
+			"Modality":    freeField[5:7],
+			"Installment": freeField[22:25],
+		},
+	}, nil
+}
+
+func isFreeField(freeField string) bool {
+	return len(freeField) == 25 && utils.OnlyNumbers(freeField) == freeField
+}
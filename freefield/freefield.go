@@ -0,0 +1,72 @@
+package freefield
+
+import (
+	"errors"
+	"github.com/fonini/go-boleto-utils/utils"
+	"sync"
+)
+
+var (
+	// ErrInvalidFreeField is returned when the free field is not 25 digits long
+	ErrInvalidFreeField = errors.New("invalid free field")
+	// ErrNoDecoder is returned when no decoder is registered for the bank
+	ErrNoDecoder = errors.New("no free field decoder for bank")
+)
+
+// Decoder decodes the 25-digit free field (campo livre) of a bank's boletos
+type Decoder interface {
+	Decode(freeField string) (*utils.FreeField, error)
+}
+
+// DecoderFunc adapts a function to the Decoder interface
+type DecoderFunc func(freeField string) (*utils.FreeField, error)
+
+// Decode calls f(freeField)
+func (f DecoderFunc) Decode(freeField string) (*utils.FreeField, error) {
+	return f(freeField)
+}
+
+var (
+	mu       sync.RWMutex
+	decoders = map[string]Decoder{
+		"001": BancoDoBrasil{},
+		"033": Santander{},
+		"104": Caixa{},
+		"237": Bradesco{},
+		"341": Itau{},
+		"748": Sicredi{},
+		"756": Sicoob{},
+	}
+)
+
+// Register sets the decoder used for boletos issued by bankCode, replacing any previous one
+func Register(bankCode string, decoder Decoder) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	decoders[bankCode] = decoder
+}
+
+// Lookup returns the decoder registered for bankCode
+func Lookup(bankCode string) (Decoder, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	decoder, ok := decoders[bankCode]
+
+	return decoder, ok
+}
+
+// Decode decodes freeField with the decoder registered for bankCode
+func Decode(bankCode string, freeField string) (*utils.FreeField, error) {
+	decoder, ok := Lookup(bankCode)
+	if !ok {
+		return nil, ErrNoDecoder
+	}
+
+	if len(freeField) != 25 || utils.OnlyNumbers(freeField) != freeField {
+		return nil, ErrInvalidFreeField
+	}
+
+	return decoder.Decode(freeField)
+}
@@ -0,0 +1,121 @@
+package freefield
+
+import (
+	"errors"
+	"github.com/fonini/go-boleto-utils/utils"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValues_Decode(t *testing.T) {
+	tests := []struct {
+		bankCode  string
+		freeField string
+		want      *utils.FreeField
+	}{
+		{"001", "0000003337176000063937217",
+			&utils.FreeField{Agreement: "3337176", OurNumber: "33371760000639372", Wallet: "17"},
+		},
+		{"001", "1234561234512340001234518",
+			&utils.FreeField{Agreement: "123456", OurNumber: "12345612345", Agency: "1234", Account: "00012345", Wallet: "18"},
+		},
+		{"001", "1234561234567890123456721",
+			&utils.FreeField{Agreement: "123456", OurNumber: "12345678901234567", Extra: map[string]string{"Service": "21"}},
+		},
+		{"033", "9123456700000001234520101",
+			&utils.FreeField{Agreement: "1234567", OurNumber: "000000012345", OurNumberDigit: "2", Wallet: "101", Extra: map[string]string{"IOF": "0"}},
+		},
+		{"104", "1234567000100040000000426",
+			&utils.FreeField{
+				Agreement: "123456",
+				Wallet:    "1",
+				OurNumber: "14000000000000042",
+				Extra:     map[string]string{"AgreementDigit": "7", "Issuer": "4", "FreeFieldDigit": "6"},
+			},
+		},
+		{"237", "3381260005963342100006330",
+			&utils.FreeField{Agency: "3381", Wallet: "26", OurNumber: "00059633421", Account: "0000633"},
+		},
+		{"341", "1092664672997197273480000",
+			&utils.FreeField{Wallet: "109", OurNumber: "26646729", OurNumberDigit: "9", Agency: "7197", Account: "27348", AccountDigit: "0"},
+		},
+		{"748", "1121577703702280000282105",
+			&utils.FreeField{
+				Wallet:         "1",
+				OurNumber:      "21577703",
+				OurNumberDigit: "7",
+				Agency:         "0228",
+				Agreement:      "00282",
+				Extra:          map[string]string{"CollectionType": "1", "Post": "00", "FreeFieldDigit": "5"},
+			},
+		},
+		{"756", "1303601034672115923845001",
+			&utils.FreeField{
+				Wallet:         "1",
+				Agency:         "3036",
+				Agreement:      "0346721",
+				OurNumber:      "1592384",
+				OurNumberDigit: "5",
+				Extra:          map[string]string{"Modality": "01", "Installment": "001"},
+			},
+		},
+		{"461", "1110000000000263505704101",
+			nil,
+		},
+		{"341", "109266467299719727348000",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		v, _ := Decode(tt.bankCode, tt.freeField)
+
+		if diff := cmp.Diff(tt.want, v); diff != "" {
+			t.Errorf("Decode(%v, %v) mismatch:\n%s", tt.bankCode, tt.freeField, diff)
+		}
+	}
+}
+
+func TestValues_BancoDoBrasilAgreementLength(t *testing.T) {
+	v, _ := BancoDoBrasil{AgreementLength: 4}.Decode("1234123456712340001234517")
+	want := &utils.FreeField{Agreement: "1234", OurNumber: "12341234567", Agency: "1234", Account: "00012345", Wallet: "17"}
+
+	if diff := cmp.Diff(want, v); diff != "" {
+		t.Errorf("BancoDoBrasil.Decode() mismatch:\n%s", diff)
+	}
+
+	if _, err := (BancoDoBrasil{AgreementLength: 5}).Decode("1234123456712340001234517"); !errors.Is(err, ErrInvalidFreeField) {
+		t.Errorf("BancoDoBrasil.Decode() = %v, want %v", err, ErrInvalidFreeField)
+	}
+}
+
+func TestValues_Register(t *testing.T) {
+	decoder := DecoderFunc(func(freeField string) (*utils.FreeField, error) {
+		return &utils.FreeField{OurNumber: freeField[:10]}, nil
+	})
+
+	if _, err := Decode("999", "1234567890123456789012345"); !errors.Is(err, ErrNoDecoder) {
+		t.Fatalf("Decode() = %v, want %v", err, ErrNoDecoder)
+	}
+
+	Register("999", decoder)
+	defer func() {
+		mu.Lock()
+		delete(decoders, "999")
+		mu.Unlock()
+	}()
+
+	v, err := Decode("999", "1234567890123456789012345")
+	if err != nil {
+		t.Fatalf("Decode() returned %v", err)
+	}
+
+	if v.OurNumber != "1234567890" {
+		t.Errorf("Decode() OurNumber = %v, want 1234567890", v.OurNumber)
+	}
+
+	if _, err := Decode("999", "12345"); !errors.Is(err, ErrInvalidFreeField) {
+		t.Errorf("Decode() = %v, want %v", err, ErrInvalidFreeField)
+	}
+}
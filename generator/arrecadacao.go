@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+)
+
+var (
+	// ErrInvalidSegment is returned when the segment cannot be used in arrecadação codes
+	ErrInvalidSegment = errors.New("invalid segment")
+	// ErrInvalidValueIdentifier is returned when the value identifier is not 6, 7, 8 or 9
+	ErrInvalidValueIdentifier = errors.New("invalid value identifier")
+	// ErrInvalidCompanyID is returned when the company id is not a 4-digit FEBRABAN code,
+	// or an 8-digit CNPJ root for the PaymentBooklets segment
+	ErrInvalidCompanyID = errors.New("invalid company id")
+)
+
+// Value identifiers of arrecadação codes: effective values are amounts in reais, reference
+// values are quantities of an index. Modes 6 and 7 use module 10 check digits, 8 and 9 module 11.
+const (
+	EffectiveValueMod10 = 6
+	ReferenceValueMod10 = 7
+	EffectiveValueMod11 = 8
+	ReferenceValueMod11 = 9
+)
+
+// ArrecadacaoRequest holds the fields of an arrecadação code to be generated. CompanyID is the
+// 4-digit FEBRABAN code followed by a 25-digit FreeField, or for the PaymentBooklets segment
+// the 8-digit CNPJ root followed by a 21-digit FreeField.
+type ArrecadacaoRequest struct {
+	Segment         utils.BoletoType
+	ValueIdentifier int
+	Amount          utils.Money
+	CompanyID       string
+	FreeField       string
+}
+
+// GenerateArrecadacao builds the 44-digit barcode and the 48-digit digitable line of an
+// arrecadação code, computing the check digits with the module selected by the value identifier
+func GenerateArrecadacao(request ArrecadacaoRequest) (*Result, error) {
+	segment, ok := utils.SegmentCodes[request.Segment]
+	if !ok {
+		return nil, ErrInvalidSegment
+	}
+
+	if request.ValueIdentifier < EffectiveValueMod10 || request.ValueIdentifier > ReferenceValueMod11 {
+		return nil, ErrInvalidValueIdentifier
+	}
+
+	companyIDSize, freeFieldSize := 4, 25
+	if segment == parser.CNPJSegment {
+		companyIDSize, freeFieldSize = 8, 21
+	}
+
+	if len(request.CompanyID) != companyIDSize || utils.OnlyNumbers(request.CompanyID) != request.CompanyID {
+		return nil, ErrInvalidCompanyID
+	}
+
+	if len(request.FreeField) != freeFieldSize || utils.OnlyNumbers(request.FreeField) != request.FreeField {
+		return nil, ErrInvalidFreeField
+	}
+
+	amount, err := formatAmount(request.Amount, 11)
+	if err != nil {
+		return nil, err
+	}
+
+	barcode := fmt.Sprintf("8%s%d%s%s%s", segment, request.ValueIdentifier, amount, request.CompanyID, request.FreeField)
+	dv := utils.CalculateArrecadacaoVerificationDigit(request.ValueIdentifier, barcode)
+	barcode = barcode[:3] + dv + barcode[3:]
+
+	return &Result{
+		Barcode:       barcode,
+		DigitableLine: parser.FormatDigitableLine(parser.ConvertBarcodeToDigitableLine(barcode)),
+	}, nil
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+	"math"
+	"time"
+)
+
+var (
+	// ErrInvalidBankCode is returned when the bank code is not 3 digits long
+	ErrInvalidBankCode = errors.New("invalid bank code")
+	// ErrInvalidCurrency is returned when the currency is not a single digit
+	ErrInvalidCurrency = errors.New("invalid currency")
+	// ErrInvalidDueDate is returned when the due date is before utils.BaseDate
+	ErrInvalidDueDate = errors.New("invalid due date")
+	// ErrInvalidAmount is returned when the amount is negative or does not fit in 10 digits
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrInvalidFreeField is returned when the free field is not 25 digits long
+	ErrInvalidFreeField = errors.New("invalid free field")
+)
+
+// RealCurrency is the currency code of the Brazilian real
+const RealCurrency = 9
+
+// Request holds the fields of a bank boleto to be generated. A zero DueDate
+// generates the factor 0000, used by boletos without a due date.
+type Request struct {
+	BankCode  string
+	Currency  int
+	DueDate   time.Time
+	Amount    utils.Money
+	FreeField string
+}
+
+// Result holds a generated barcode and its formatted digitable line
+type Result struct {
+	Barcode       string
+	DigitableLine string
+}
+
+// Generate builds the 44-digit barcode and the 47-digit digitable line of a bank boleto,
+// computing the field check digits and the general check digit (DAC)
+func Generate(request Request) (*Result, error) {
+	if len(request.BankCode) != 3 || utils.OnlyNumbers(request.BankCode) != request.BankCode {
+		return nil, ErrInvalidBankCode
+	}
+
+	if request.Currency < 0 || request.Currency > 9 {
+		return nil, ErrInvalidCurrency
+	}
+
+	if len(request.FreeField) != 25 || utils.OnlyNumbers(request.FreeField) != request.FreeField {
+		return nil, ErrInvalidFreeField
+	}
+
+	factor, err := DueDateFactor(request.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := formatAmount(request.Amount, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	barcode := fmt.Sprintf("%s%d%04d%s%s", request.BankCode, request.Currency, factor, amount, request.FreeField)
+	dac := utils.CalculateGeneralVerificationDigit(barcode)
+	barcode = barcode[:4] + dac + barcode[4:]
+
+	return &Result{
+		Barcode:       barcode,
+		DigitableLine: parser.FormatDigitableLine(parser.ConvertBarcodeToDigitableLine(barcode)),
+	}, nil
+}
+
+// DueDateFactor returns the due date factor of date: the number of days since utils.BaseDate,
+// wrapping from 9999 back to 1000 every utils.FactorCycle days. A zero date returns 0.
+func DueDateFactor(date time.Time) (int, error) {
+	if date.IsZero() {
+		return 0, nil
+	}
+
+	baseDate, err := time.Parse(parser.BaseDateFormat, utils.BaseDate)
+	if err != nil {
+		return 0, err
+	}
+
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(math.Round(date.Sub(baseDate).Hours() / 24))
+
+	if days < 1 {
+		return 0, ErrInvalidDueDate
+	}
+
+	if days < 1000 {
+		return days, nil
+	}
+
+	return (days-1000)%utils.FactorCycle + 1000, nil
+}
+
+// formatAmount formats amount in centavos, left padded with zeros to size digits
+func formatAmount(amount utils.Money, size int) (string, error) {
+	value := fmt.Sprintf("%0*d", size, int64(amount))
+
+	if amount < 0 || len(value) > size {
+		return "", ErrInvalidAmount
+	}
+
+	return value, nil
+}
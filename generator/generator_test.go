@@ -0,0 +1,247 @@
+package generator
+
+import (
+	"errors"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+	"github.com/fonini/go-boleto-utils/validator"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValues_Generate(t *testing.T) {
+	loc, _ := time.LoadLocation("UTC")
+
+	tests := []struct {
+		input Request
+		want  *Result
+	}{
+		{Request{BankCode: "341",
+			Currency:  RealCurrency,
+			DueDate:   time.Date(2024, 11, 20, 0, 0, 0, 0, loc),
+			Amount:    500,
+			FreeField: "1092664672997197273480000",
+		},
+			&Result{Barcode: "34191990600000005001092664672997197273480000",
+				DigitableLine: "34191.09263 64672.997190 72734.800005 1 99060000000500",
+			},
+		},
+		{Request{BankCode: "748",
+			Currency:  RealCurrency,
+			DueDate:   time.Date(2024, 12, 5, 0, 0, 0, 0, loc),
+			Amount:    84536,
+			FreeField: "1121577703702280000282105",
+		},
+			&Result{Barcode: "74898992100000845361121577703702280000282105",
+				DigitableLine: "74891.12156 77703.702280 00002.821056 8 99210000084536",
+			},
+		},
+		{Request{BankCode: "739",
+			Currency:  RealCurrency,
+			FreeField: "0000000001223329012613034",
+		},
+			&Result{Barcode: "73994000000000000000000000001223329012613034",
+				DigitableLine: "73990.00004 00001.223320 90126.130344 4 00000000000000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := Generate(tt.input)
+
+		if err != nil {
+			t.Fatalf("Generate(%+v) returned %v", tt.input, err)
+		}
+
+		if diff := cmp.Diff(tt.want, result); diff != "" {
+			t.Errorf("Generate(%+v) mismatch:\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestValues_GenerateRoundTrip(t *testing.T) {
+	loc, _ := time.LoadLocation("UTC")
+
+	tests := []Request{
+		{BankCode: "001", Currency: RealCurrency, DueDate: time.Date(2025, 2, 21, 0, 0, 0, 0, loc), Amount: 116037, FreeField: "0000003337176000063937217"},
+		{BankCode: "237", Currency: RealCurrency, DueDate: time.Date(2025, 2, 22, 0, 0, 0, 0, loc), Amount: 29, FreeField: "3381260005963342100006330"},
+		{BankCode: "756", Currency: RealCurrency, DueDate: time.Date(2031, 8, 15, 0, 0, 0, 0, loc), Amount: 9999999999, FreeField: "1303601034672115923845001"},
+	}
+
+	for _, tt := range tests {
+		result, err := Generate(tt)
+
+		if err != nil {
+			t.Fatalf("Generate(%+v) returned %v", tt, err)
+		}
+
+		for _, code := range []string{result.Barcode, result.DigitableLine} {
+			if !validator.Validate(code) {
+				t.Errorf("Validate(%s) = false, want true", code)
+			}
+
+			boleto, err := parser.ParseWithOptions(code, parser.Options{ReferenceDate: tt.DueDate})
+
+			if err != nil {
+				t.Fatalf("Parse(%s) returned %v", code, err)
+			}
+
+			got := Request{
+				BankCode:  boleto.IssuerBankCode,
+				Currency:  boleto.Currency,
+				DueDate:   boleto.DueDate,
+				Amount:    boleto.Amount,
+				FreeField: boleto.IssuerReserved1 + boleto.IssuerReserved2 + boleto.IssuerReserved3,
+			}
+
+			if diff := cmp.Diff(tt, got); diff != "" {
+				t.Errorf("Parse(%s) mismatch:\n%s", code, diff)
+			}
+		}
+	}
+}
+
+func TestValues_GenerateErrors(t *testing.T) {
+	loc, _ := time.LoadLocation("UTC")
+
+	tests := []struct {
+		input Request
+		want  error
+	}{
+		{Request{BankCode: "34", Currency: RealCurrency, FreeField: "1092664672997197273480000"}, ErrInvalidBankCode},
+		{Request{BankCode: "341", Currency: 10, FreeField: "1092664672997197273480000"}, ErrInvalidCurrency},
+		{Request{BankCode: "341", Currency: RealCurrency, FreeField: "109266467299719727348000"}, ErrInvalidFreeField},
+		{Request{BankCode: "341", Currency: RealCurrency, FreeField: "1092664672997197273480000", Amount: -100}, ErrInvalidAmount},
+		{Request{BankCode: "341", Currency: RealCurrency, FreeField: "1092664672997197273480000", Amount: 10000000000}, ErrInvalidAmount},
+		{Request{BankCode: "341", Currency: RealCurrency, FreeField: "1092664672997197273480000", DueDate: time.Date(1990, 1, 1, 0, 0, 0, 0, loc)}, ErrInvalidDueDate},
+	}
+
+	for _, tt := range tests {
+		if _, err := Generate(tt.input); !errors.Is(err, tt.want) {
+			t.Errorf("Generate(%+v) = %v, want %v", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestValues_DueDateFactor(t *testing.T) {
+	loc, _ := time.LoadLocation("UTC")
+
+	tests := []struct {
+		input time.Time
+		want  int
+	}{
+		{time.Time{}, 0},
+		{time.Date(2000, 7, 3, 0, 0, 0, 0, loc), 1000},
+		{time.Date(2025, 2, 21, 0, 0, 0, 0, loc), 9999},
+		{time.Date(2025, 2, 22, 0, 0, 0, 0, loc), 1000},
+		{time.Date(2024, 11, 20, 15, 30, 0, 0, loc), 9906},
+	}
+
+	for _, tt := range tests {
+		if got, _ := DueDateFactor(tt.input); got != tt.want {
+			t.Errorf("DueDateFactor(%s) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestValues_GenerateArrecadacao(t *testing.T) {
+	tests := []struct {
+		input ArrecadacaoRequest
+		want  *Result
+	}{
+		{ArrecadacaoRequest{Segment: utils.Sanitation,
+			ValueIdentifier: EffectiveValueMod10,
+			Amount:          36456,
+			CompanyID:       "0798",
+			FreeField:       "0000100023510382202411671",
+		},
+			&Result{Barcode: "82670000003645607980000100023510382202411671",
+				DigitableLine: "82670000003-5 64560798000-2 01000235103-8 82202411671-4",
+			},
+		},
+		{ArrecadacaoRequest{Segment: utils.GovernmentAgencies,
+			ValueIdentifier: EffectiveValueMod11,
+			Amount:          8374,
+			CompanyID:       "0385",
+			FreeField:       "2424307012424185141630306",
+		},
+			&Result{Barcode: "85860000000837403852424307012424185141630306",
+				DigitableLine: "85860000000-4 83740385242-0 43070124241-5 85141630306-0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := GenerateArrecadacao(tt.input)
+
+		if err != nil {
+			t.Fatalf("GenerateArrecadacao(%+v) returned %v", tt.input, err)
+		}
+
+		if diff := cmp.Diff(tt.want, result); diff != "" {
+			t.Errorf("GenerateArrecadacao(%+v) mismatch:\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestValues_GenerateArrecadacaoRoundTrip(t *testing.T) {
+	tests := []ArrecadacaoRequest{
+		{Segment: utils.CityHalls, ValueIdentifier: EffectiveValueMod10, Amount: 116037, CompanyID: "0001", FreeField: "2024000000000000123456789"},
+		{Segment: utils.TrafficFines, ValueIdentifier: EffectiveValueMod11, Amount: 29, CompanyID: "9999", FreeField: "0000000000000000000000001"},
+		{Segment: utils.PaymentBooklets, ValueIdentifier: ReferenceValueMod11, Amount: 1250, CompanyID: "12345678", FreeField: "000000000000000000042"},
+		{Segment: utils.Telecommunications, ValueIdentifier: ReferenceValueMod10, Amount: 99999999999, CompanyID: "0079", FreeField: "1000111939897192410154434"},
+	}
+
+	for _, tt := range tests {
+		result, err := GenerateArrecadacao(tt)
+
+		if err != nil {
+			t.Fatalf("GenerateArrecadacao(%+v) returned %v", tt, err)
+		}
+
+		for _, code := range []string{result.Barcode, result.DigitableLine} {
+			if !validator.Validate(code) {
+				t.Errorf("Validate(%s) = false, want true", code)
+			}
+
+			arrecadacao, err := parser.ParseArrecadacao(code)
+
+			if err != nil {
+				t.Fatalf("ParseArrecadacao(%s) returned %v", code, err)
+			}
+
+			got := ArrecadacaoRequest{
+				Segment:         arrecadacao.Segment,
+				ValueIdentifier: arrecadacao.ValueIdentifier,
+				Amount:          arrecadacao.Amount,
+				CompanyID:       arrecadacao.CompanyID,
+				FreeField:       arrecadacao.FreeField,
+			}
+
+			if diff := cmp.Diff(tt, got); diff != "" {
+				t.Errorf("ParseArrecadacao(%s) mismatch:\n%s", code, diff)
+			}
+		}
+	}
+}
+
+func TestValues_GenerateArrecadacaoErrors(t *testing.T) {
+	tests := []struct {
+		input ArrecadacaoRequest
+		want  error
+	}{
+		{ArrecadacaoRequest{Segment: utils.Bank, ValueIdentifier: 6, CompanyID: "0001", FreeField: "2024000000000000123456789"}, ErrInvalidSegment},
+		{ArrecadacaoRequest{Segment: utils.CityHalls, ValueIdentifier: 5, CompanyID: "0001", FreeField: "2024000000000000123456789"}, ErrInvalidValueIdentifier},
+		{ArrecadacaoRequest{Segment: utils.CityHalls, ValueIdentifier: 6, CompanyID: "12345678", FreeField: "000000000000000000042"}, ErrInvalidCompanyID},
+		{ArrecadacaoRequest{Segment: utils.PaymentBooklets, ValueIdentifier: 6, CompanyID: "12345678", FreeField: "2024000000000000123456789"}, ErrInvalidFreeField},
+		{ArrecadacaoRequest{Segment: utils.CityHalls, ValueIdentifier: 6, Amount: 100000000000, CompanyID: "0001", FreeField: "2024000000000000123456789"}, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		if _, err := GenerateArrecadacao(tt.input); !errors.Is(err, tt.want) {
+			t.Errorf("GenerateArrecadacao(%+v) = %v, want %v", tt.input, err, tt.want)
+		}
+	}
+}
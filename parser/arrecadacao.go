@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/fonini/go-boleto-utils/utils"
+	"strconv"
+)
+
+// CNPJSegment is the segment whose companies are identified by the 8-digit CNPJ root
+// instead of the 4-digit FEBRABAN code.
+const CNPJSegment = "6"
+
+// IsArrecadacao reports whether code is a FEBRABAN arrecadação (convênio) barcode or digitable line
+func IsArrecadacao(code string) bool {
+	code = utils.OnlyNumbers(code)
+
+	return (len(code) == 44 || len(code) == 48) && code[0] == '8'
+}
+
+// ParseArrecadacao parses an arrecadação digitable line or barcode into an Arrecadacao struct
+func ParseArrecadacao(code string) (*utils.Arrecadacao, error) {
+	line := utils.OnlyNumbers(code)
+
+	if len(line) != 44 && len(line) != 48 {
+		return nil, &ParseError{Input: code, Field: "Length", Offset: -1, Err: ErrUnknownCode}
+	}
+
+	if !IsArrecadacao(line) {
+		return nil, newParseError(code, 0, "ProductID", ErrNotArrecadacao)
+	}
+
+	if _, err := parseValueIdentifier(utils.Substr(line, 2, 1)); err != nil {
+		return nil, newParseError(code, 2, "ValueIdentifier", err)
+	}
+
+	codeType, _ := GetCodeType(line)
+
+	if codeType == Barcode {
+		line, _ = convertArrecadacaoBarcodeToDigitableLine(line)
+	}
+
+	return parseArrecadacaoLine(code, line, codeType)
+}
+
+func parseArrecadacaoLine(input string, line string, codeType utils.BoletoCodeType) (*utils.Arrecadacao, error) {
+	var arrecadacao utils.Arrecadacao
+
+	barcode := line[0:11] + line[12:23] + line[24:35] + line[36:47]
+
+	arrecadacao.ProductID = utils.Substr(barcode, 0, 1)
+	arrecadacao.SegmentCode = utils.Substr(barcode, 1, 1)
+	arrecadacao.Segment = utils.Segments[arrecadacao.SegmentCode]
+
+	// The line is 48 digits long and its value identifier was checked by ParseArrecadacao
+	arrecadacao.ValueIdentifier, _ = strconv.Atoi(utils.Substr(barcode, 2, 1))
+	arrecadacao.GeneralCheckDigit, _ = strconv.Atoi(utils.Substr(barcode, 3, 1))
+
+	amount, err := parseAmount(utils.Substr(barcode, 4, 11))
+	if err != nil {
+		return nil, newParseError(input, arrecadacaoInputIndex(codeType, 4), "Amount", ErrInvalidAmount)
+	}
+	arrecadacao.Amount = amount
+
+	if arrecadacao.SegmentCode == CNPJSegment {
+		arrecadacao.CompanyID = utils.Substr(barcode, 15, 8)
+		arrecadacao.FreeField = utils.Substr(barcode, 23, 21)
+	} else {
+		arrecadacao.CompanyID = utils.Substr(barcode, 15, 4)
+		arrecadacao.FreeField = utils.Substr(barcode, 19, 25)
+	}
+
+	arrecadacao.CheckDigit1, _ = strconv.Atoi(utils.Substr(line, 11, 1))
+	arrecadacao.CheckDigit2, _ = strconv.Atoi(utils.Substr(line, 23, 1))
+	arrecadacao.CheckDigit3, _ = strconv.Atoi(utils.Substr(line, 35, 1))
+	arrecadacao.CheckDigit4, _ = strconv.Atoi(utils.Substr(line, 47, 1))
+
+	arrecadacao.CodeType = codeType
+
+	return &arrecadacao, nil
+}
+
+// arrecadacaoInputIndex translates an index of the arrecadação barcode to the representation of the input
+func arrecadacaoInputIndex(codeType utils.BoletoCodeType, index int) int {
+	if codeType == DigitableLine {
+		return index + index/11
+	}
+
+	return index
+}
+
+// parseValueIdentifier validates the third digit of an arrecadação code. 6 and 8 mean the
+// amount is an effective value in reais, 7 and 9 mean it is a reference value (e.g. an index
+// quantity); 6 and 7 use module 10 check digits, 8 and 9 use module 11.
+func parseValueIdentifier(digit string) (int, error) {
+	switch digit {
+	case "6", "7", "8", "9":
+		return strconv.Atoi(digit)
+	default:
+		return 0, ErrInvalidValueIdentifier
+	}
+}
+
+func convertArrecadacaoBarcodeToDigitableLine(barcode string) (string, error) {
+	valueIdentifier, err := parseValueIdentifier(utils.Substr(barcode, 2, 1))
+	if err != nil {
+		return "", err
+	}
+
+	var line string
+
+	for i := 0; i < 44; i += 11 {
+		block := barcode[i : i+11]
+		line += block + utils.CalculateArrecadacaoVerificationDigit(valueIdentifier, block)
+	}
+
+	return line, nil
+}
+
+func convertArrecadacaoDigitableLineToBarcode(input string, line string) (string, error) {
+	valueIdentifier, err := parseValueIdentifier(line[2:3])
+	if err != nil {
+		return "", newParseError(input, 2, "ValueIdentifier", err)
+	}
+
+	var barcode string
+
+	for i := 0; i < 48; i += 12 {
+		block := line[i : i+11]
+
+		if utils.CalculateArrecadacaoVerificationDigit(valueIdentifier, block) != line[i+11:i+12] {
+			return "", newParseError(input, i+11, fmt.Sprintf("CheckDigit%d", i/12+1), ErrInvalidCheckDigit)
+		}
+
+		barcode += block
+	}
+
+	if utils.CalculateArrecadacaoVerificationDigit(valueIdentifier, barcode[:3]+barcode[4:]) != barcode[3:4] {
+		return "", newParseError(input, 3, "GeneralCheckDigit", ErrInvalidCheckDigit)
+	}
+
+	return barcode, nil
+}
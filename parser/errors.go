@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/utils"
+)
+
+var (
+	// ErrUnknownCode is returned when the code is neither a barcode nor a digitable line
+	ErrUnknownCode = errors.New("unknown code")
+	// ErrArrecadacao is returned by Parse for arrecadação codes, which must be parsed with ParseArrecadacao
+	ErrArrecadacao = errors.New("arrecadação code, use ParseArrecadacao")
+	// ErrNotArrecadacao is returned by ParseArrecadacao for codes that are not arrecadação codes
+	ErrNotArrecadacao = errors.New("not an arrecadação code")
+	// ErrInvalidDigit is returned when a single-digit field cannot be read
+	ErrInvalidDigit = errors.New("invalid digit")
+	// ErrInvalidCheckDigit is returned when a check digit does not match the digits it protects
+	ErrInvalidCheckDigit = errors.New("invalid check digit")
+	// ErrInvalidValueIdentifier is returned when an arrecadação value identifier is not 6, 7, 8 or 9
+	ErrInvalidValueIdentifier = errors.New("invalid value identifier")
+	// ErrInvalidDueDate is returned when the due date factor cannot be read
+	ErrInvalidDueDate = errors.New("invalid due date factor")
+	// ErrInvalidAmount is returned when the amount cannot be read
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// ParseError records the field that failed to parse, its character offset in the
+// original input (-1 when the failure is not tied to a position) and the input itself.
+// Err holds one of the sentinel errors of this package.
+type ParseError struct {
+	Input  string
+	Field  string
+	Offset int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("parse %q: %s: %v", e.Input, e.Field, e.Err)
+	}
+
+	return fmt.Sprintf("parse %q: %s at offset %d: %v", e.Input, e.Field, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError builds a ParseError for the field starting at index of the
+// normalized digits, translating it to a character offset in input.
+func newParseError(input string, index int, field string, err error) *ParseError {
+	return &ParseError{Input: input, Field: field, Offset: inputOffset(input, index), Err: err}
+}
+
+// inputOffset returns the character offset in input of the digit at index of utils.OnlyNumbers(input)
+func inputOffset(input string, index int) int {
+	if index < 0 {
+		return -1
+	}
+
+	digits := 0
+
+	for i, r := range []rune(input) {
+		if r < '0' || r > '9' {
+			continue
+		}
+
+		if digits == index {
+			return i
+		}
+
+		digits++
+	}
+
+	return -1
+}
+
+// barcodeIndex maps an index of a bank digitable line to the same digit in the barcode,
+// or -1 for the field check digits, which only exist in the line.
+func barcodeIndex(lineIndex int) int {
+	switch {
+	case lineIndex < 4:
+		return lineIndex
+	case lineIndex < 9:
+		return lineIndex + 15
+	case lineIndex >= 10 && lineIndex < 20:
+		return lineIndex + 14
+	case lineIndex >= 21 && lineIndex < 31:
+		return lineIndex + 13
+	case lineIndex == 32:
+		return 4
+	case lineIndex > 32:
+		return lineIndex - 28
+	default:
+		return -1
+	}
+}
+
+// inputIndex translates an index of the bank digitable line to the representation of the input
+func inputIndex(codeType utils.BoletoCodeType, index int) int {
+	if codeType == Barcode {
+		return barcodeIndex(index)
+	}
+
+	return index
+}
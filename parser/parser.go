@@ -1,9 +1,11 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
+	"github.com/fonini/go-boleto-utils/banks"
+	"github.com/fonini/go-boleto-utils/freefield"
 	"github.com/fonini/go-boleto-utils/utils"
+	"math"
 	"strconv"
 	"time"
 )
@@ -16,28 +18,50 @@ const (
 	BaseDateFormat = "2006-01-02 15:04:05"
 )
 
-// Parse parses a digitable line or a barcode into a Boleto struct
+// Options configures how Parse resolves fields that depend on context
+type Options struct {
+	// ReferenceDate anchors the due date factor, which restarted at 1000 on 2025-02-22
+	// and repeats every 9000 days: the factor resolves to the candidate date closest to
+	// ReferenceDate. Defaults to utils.FactorResetDate, which resolves factors to the
+	// fixed window from 2012-10-29 (factor 5501) to 2037-06-19 (factor 5500), whatever
+	// the day Parse runs on.
+	ReferenceDate time.Time
+}
+
+// Parse parses a digitable line or a barcode into a Boleto struct, resolving the due date
+// factor to the default window of Options.ReferenceDate
 func Parse(code string) (*utils.Boleto, error) {
+	return ParseWithOptions(code, Options{})
+}
+
+// ParseWithOptions parses a digitable line or a barcode into a Boleto struct using options
+func ParseWithOptions(code string, options Options) (*utils.Boleto, error) {
 	line := utils.OnlyNumbers(code)
 
+	if IsArrecadacao(line) {
+		return nil, newParseError(code, 0, "ProductID", ErrArrecadacao)
+	}
+
 	codeType, err := GetCodeType(line)
 
 	if err != nil {
-		return nil, err
+		return nil, &ParseError{Input: code, Field: "Length", Offset: -1, Err: err}
 	}
 
 	if codeType == Barcode {
 		line = ConvertBarcodeToDigitableLine(line)
 	}
 
-	boleto, err := parseDigitableLine(line)
+	if options.ReferenceDate.IsZero() {
+		options.ReferenceDate, _ = time.Parse(BaseDateFormat, utils.FactorResetDate)
+	}
+
+	boleto, err := parseDigitableLine(code, line, codeType, options.ReferenceDate)
 
 	if err != nil {
 		return nil, err
 	}
 
-	boleto.CodeType = codeType
-
 	return boleto, nil
 }
 
@@ -50,7 +74,7 @@ func GetCodeType(code string) (utils.BoletoCodeType, error) {
 	case 46, 47, 48:
 		return DigitableLine, nil
 	default:
-		return Unknown, errors.New("unknown code")
+		return Unknown, ErrUnknownCode
 	}
 }
 
@@ -60,64 +84,82 @@ func GetBoletoType(code string) utils.BoletoType {
 	if code[len(code)-14:] == "00000000000000" || utils.Substr(code, 5, 14) == "00000000000000" {
 		return utils.CreditCard
 	} else if utils.Substr(code, 0, 1) == "8" {
-		digit := utils.Substr(code, 1, 1)
-
-		switch digit {
-		case "1":
-			return utils.CityHalls
-		case "2":
-			return utils.Sanitation
-		case "3":
-			return utils.ElectricityAndGas
-		case "4":
-			return utils.Telecommunications
-		case "5":
-			return utils.GovernmentAgencies
-		case "6", "9":
-			return utils.PaymentBooklets
-		case "7":
-			return utils.TrafficFines
+		if segment, ok := utils.Segments[utils.Substr(code, 1, 1)]; ok {
+			return segment
 		}
 	}
 
 	return utils.Bank
 }
 
-func parseDigitableLine(line string) (*utils.Boleto, error) {
+func parseDigitableLine(input string, line string, codeType utils.BoletoCodeType, referenceDate time.Time) (*utils.Boleto, error) {
 	var boleto utils.Boleto
+	var err error
 
 	boleto.IssuerBankCode = utils.Substr(line, 0, 3)
-	boleto.IssuerBankName = utils.Banks[boleto.IssuerBankCode]
+	if bank, err := banks.ByCode(boleto.IssuerBankCode); err == nil {
+		boleto.IssuerBankName = bank.Name
+	} else {
+		boleto.UnknownIssuer = true
+	}
 
-	boleto.Currency, _ = strconv.Atoi(utils.Substr(line, 3, 1))
+	if boleto.Currency, err = parseDigit(input, line, codeType, 3, "Currency"); err != nil {
+		return nil, err
+	}
 
 	boleto.IssuerReserved1 = utils.Substr(line, 4, 5)
-	boleto.CheckDigit1, _ = strconv.Atoi(utils.Substr(line, 9, 1))
+	if boleto.CheckDigit1, err = parseDigit(input, line, codeType, 9, "CheckDigit1"); err != nil {
+		return nil, err
+	}
 
 	boleto.IssuerReserved2 = utils.Substr(line, 10, 10)
-	boleto.CheckDigit2, _ = strconv.Atoi(utils.Substr(line, 20, 1))
+	if boleto.CheckDigit2, err = parseDigit(input, line, codeType, 20, "CheckDigit2"); err != nil {
+		return nil, err
+	}
 
 	boleto.IssuerReserved3 = utils.Substr(line, 21, 10)
-	boleto.CheckDigit3, _ = strconv.Atoi(utils.Substr(line, 31, 1))
+	if boleto.CheckDigit3, err = parseDigit(input, line, codeType, 31, "CheckDigit3"); err != nil {
+		return nil, err
+	}
+
+	if boleto.GeneralCheckDigit, err = parseDigit(input, line, codeType, 32, "GeneralCheckDigit"); err != nil {
+		return nil, err
+	}
 
-	boleto.GeneralCheckDigit, _ = strconv.Atoi(utils.Substr(line, 32, 1))
+	// Banks without a registered decoder, or free fields it rejects, leave FreeField nil
+	boleto.FreeField, _ = freefield.Decode(boleto.IssuerBankCode, boleto.IssuerReserved1+boleto.IssuerReserved2+boleto.IssuerReserved3)
 
-	dueDate, err := calculateDueDate(utils.Substr(line, 33, 4))
+	dueDate, err := calculateDueDate(utils.Substr(line, 33, 4), referenceDate)
 	if err != nil {
-		return nil, err
+		return nil, newParseError(input, inputIndex(codeType, 33), "DueDate", ErrInvalidDueDate)
 	}
 	boleto.DueDate = dueDate
 
 	amount, err := parseAmount(utils.Substr(line, 37, 10))
 	if err != nil {
-		return nil, err
+		return nil, newParseError(input, inputIndex(codeType, 37), "Amount", ErrInvalidAmount)
 	}
 	boleto.Amount = amount
 
+	boleto.CodeType = codeType
+
 	return &boleto, nil
 }
 
-func calculateDueDate(dueDateStr string) (time.Time, error) {
+// parseDigit reads the single digit at index of the digitable line
+func parseDigit(input string, line string, codeType utils.BoletoCodeType, index int, field string) (int, error) {
+	digit, err := strconv.Atoi(utils.Substr(line, index, 1))
+	if err != nil {
+		return 0, newParseError(input, inputIndex(codeType, index), field, ErrInvalidDigit)
+	}
+
+	return digit, nil
+}
+
+// calculateDueDate resolves a due date factor. Factor 0000 (no due date) and factors
+// below 1000 (before 2000-07-03) are counted from utils.BaseDate; factors from 1000 on
+// repeat every utils.FactorCycle days, so the cycle closest to referenceDate is used.
+func calculateDueDate(dueDateStr string, referenceDate time.Time) (time.Time, error) {
 	dueDate, err := strconv.Atoi(dueDateStr)
 	if err != nil {
 		return time.Time{}, err
@@ -127,18 +169,39 @@ func calculateDueDate(dueDateStr string) (time.Time, error) {
 	if err != nil {
 		return time.Time{}, err
 	}
-	return dateFactor.AddDate(0, 0, dueDate), nil
+
+	if dueDate < 1000 {
+		return dateFactor.AddDate(0, 0, dueDate), nil
+	}
+
+	first := dateFactor.AddDate(0, 0, dueDate)
+	days := referenceDate.Sub(first).Hours() / 24
+	cycle := int(math.Round(days / utils.FactorCycle))
+
+	if cycle < 0 {
+		cycle = 0
+	}
+
+	return first.AddDate(0, 0, cycle*utils.FactorCycle), nil
 }
 
-func parseAmount(amountStr string) (float64, error) {
-	amount, err := strconv.ParseFloat(amountStr, 64)
+func parseAmount(amountStr string) (utils.Money, error) {
+	amount, err := strconv.ParseInt(amountStr, 10, 64)
 	if err != nil {
 		return 0, err
 	}
-	return amount / 100, nil
+	return utils.Money(amount), nil
 }
 
+// ConvertBarcodeToDigitableLine converts a 44-digit barcode into its digitable line, computing
+// the field check digits. Arrecadação barcodes produce a 48-digit line, or an empty string
+// when their value identifier is invalid.
 func ConvertBarcodeToDigitableLine(barcode string) string {
+	if IsArrecadacao(barcode) {
+		line, _ := convertArrecadacaoBarcodeToDigitableLine(barcode)
+		return line
+	}
+
 	block1 := barcode[0:4] + barcode[19:24]
 	cd1 := utils.CalculateVerificationDigit(block1)
 
@@ -156,3 +219,53 @@ func ConvertBarcodeToDigitableLine(barcode string) string {
 		barcode[4:5], barcode[5:19],
 	)
 }
+
+// FormatDigitableLine formats a 47-digit bank line as "AAAAA.AAAAA BBBBB.BBBBBB CCCCC.CCCCCC D EEEEEEEEEEEEEE"
+// and a 48-digit arrecadação line as four "XXXXXXXXXXX-X" blocks. Other codes are returned as digits only.
+func FormatDigitableLine(code string) string {
+	line := utils.OnlyNumbers(code)
+
+	switch len(line) {
+	case 47:
+		return fmt.Sprintf("%s.%s %s.%s %s.%s %s %s",
+			line[0:5], line[5:10], line[10:15], line[15:21], line[21:26], line[26:32], line[32:33], line[33:47],
+		)
+	case 48:
+		return fmt.Sprintf("%s-%s %s-%s %s-%s %s-%s",
+			line[0:11], line[11:12], line[12:23], line[23:24], line[24:35], line[35:36], line[36:47], line[47:48],
+		)
+	default:
+		return line
+	}
+}
+
+// ConvertDigitableLineToBarcode converts a 47-digit bank line or a 48-digit arrecadação line
+// into its 44-digit barcode, checking the field and general check digits on the way
+func ConvertDigitableLineToBarcode(code string) (string, error) {
+	line := utils.OnlyNumbers(code)
+
+	switch {
+	case len(line) == 48 && IsArrecadacao(line):
+		return convertArrecadacaoDigitableLineToBarcode(code, line)
+	case len(line) == 47:
+		return convertBankDigitableLineToBarcode(code, line)
+	default:
+		return "", &ParseError{Input: code, Field: "Length", Offset: -1, Err: ErrUnknownCode}
+	}
+}
+
+func convertBankDigitableLineToBarcode(input string, line string) (string, error) {
+	for i, block := range [][2]int{{0, 9}, {10, 20}, {21, 31}} {
+		if utils.CalculateVerificationDigit(line[block[0]:block[1]]) != line[block[1]:block[1]+1] {
+			return "", newParseError(input, block[1], fmt.Sprintf("CheckDigit%d", i+1), ErrInvalidCheckDigit)
+		}
+	}
+
+	barcode := line[0:4] + line[32:47] + line[4:9] + line[10:20] + line[21:31]
+
+	if utils.CalculateGeneralVerificationDigit(barcode[:4]+barcode[5:]) != barcode[4:5] {
+		return "", newParseError(input, 32, "GeneralCheckDigit", ErrInvalidCheckDigit)
+	}
+
+	return barcode, nil
+}
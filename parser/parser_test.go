@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"github.com/fonini/go-boleto-utils/utils"
 	"testing"
 	"time"
@@ -36,8 +37,16 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       5,
 				GeneralCheckDigit: 8,
 				DueDate:           time.Date(2023, 3, 10, 0, 0, 0, 0, loc),
-				Amount:            260.35,
+				Amount:            26035,
 				CodeType:          "DIGITABLE_LINE",
+				FreeField: &utils.FreeField{
+					Agency:         "1234",
+					Account:        "12345",
+					AccountDigit:   "6",
+					Wallet:         "175",
+					OurNumber:      "12345678",
+					OurNumberDigit: "7",
+				},
 			},
 		},
 		{"23793.38128 60005.963347 21000.063301 1 74640000116037",
@@ -52,8 +61,14 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       1,
 				GeneralCheckDigit: 1,
 				DueDate:           time.Date(2018, 3, 15, 0, 0, 0, 0, loc),
-				Amount:            1160.37,
+				Amount:            116037,
 				CodeType:          "DIGITABLE_LINE",
+				FreeField: &utils.FreeField{
+					Agency:    "3381",
+					Account:   "0000633",
+					Wallet:    "26",
+					OurNumber: "00059633421",
+				},
 			},
 		},
 		{"74891.11612 00172.302267 05522.671006 3 69050000017500",
@@ -68,8 +83,16 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       6,
 				GeneralCheckDigit: 3,
 				DueDate:           time.Date(2016, 9, 2, 0, 0, 0, 0, loc),
-				Amount:            175,
+				Amount:            17500,
 				CodeType:          "DIGITABLE_LINE",
+				FreeField: &utils.FreeField{
+					Agency:         "0226",
+					Wallet:         "1",
+					Agreement:      "52267",
+					OurNumber:      "16100172",
+					OurNumberDigit: "3",
+					Extra:          map[string]string{"CollectionType": "1", "FreeFieldDigit": "0", "Post": "05"},
+				},
 			},
 		},
 		{"00190000090333717600600639372176398960000008000",
@@ -84,13 +107,18 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       6,
 				GeneralCheckDigit: 3,
 				DueDate:           time.Date(2024, 11, 10, 0, 0, 0, 0, loc),
-				Amount:            80,
+				Amount:            8000,
 				CodeType:          "DIGITABLE_LINE",
+				FreeField: &utils.FreeField{
+					Wallet:    "17",
+					Agreement: "3337176",
+					OurNumber: "33371760000639372",
+				},
 			},
 		},
 		{"46191110000000000002635057041010498940000096000",
 			&utils.Boleto{IssuerBankCode: "461",
-				IssuerBankName:    "Asaas",
+				IssuerBankName:    "Asaas Gestão Financeira Instituição de Pagamento S.A.",
 				Currency:          9,
 				IssuerReserved1:   "11100",
 				CheckDigit1:       0,
@@ -100,13 +128,13 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       0,
 				GeneralCheckDigit: 4,
 				DueDate:           time.Date(2024, 11, 8, 0, 0, 0, 0, loc),
-				Amount:            960,
+				Amount:            96000,
 				CodeType:          "DIGITABLE_LINE",
 			},
 		},
 		{"48190.00003 00005.150396 31049.960144 9 98650000025736",
 			&utils.Boleto{IssuerBankCode: "481",
-				IssuerBankName:    "Superlógica Sociedade de Crédito Direto S.A.",
+				UnknownIssuer:     true,
 				Currency:          9,
 				IssuerReserved1:   "00000",
 				CheckDigit1:       3,
@@ -116,13 +144,13 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       4,
 				GeneralCheckDigit: 9,
 				DueDate:           time.Date(2024, 10, 10, 0, 0, 0, 0, loc),
-				Amount:            257.36,
+				Amount:            25736,
 				CodeType:          "DIGITABLE_LINE",
 			},
 		},
 		{"75691303670103467211159238450015997710000096210",
 			&utils.Boleto{IssuerBankCode: "756",
-				IssuerBankName:    "Banco Cooperativo do Brasil S.A. - Bancoob",
+				IssuerBankName:    "Banco Cooperativo Sicoob S.A. - Banco Sicoob",
 				Currency:          9,
 				IssuerReserved1:   "13036",
 				CheckDigit1:       7,
@@ -132,8 +160,16 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       5,
 				GeneralCheckDigit: 9,
 				DueDate:           time.Date(2024, 7, 8, 0, 0, 0, 0, loc),
-				Amount:            962.10,
+				Amount:            96210,
 				CodeType:          "DIGITABLE_LINE",
+				FreeField: &utils.FreeField{
+					Agency:         "3036",
+					Wallet:         "1",
+					Agreement:      "0346721",
+					OurNumber:      "1592384",
+					OurNumberDigit: "5",
+					Extra:          map[string]string{"Installment": "001", "Modality": "01"},
+				},
 			},
 		},
 		{"73990.00004 00001.223320 90126.130344 4 00000000000000",
@@ -164,8 +200,16 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       5,
 				GeneralCheckDigit: 1,
 				DueDate:           time.Date(2024, 11, 20, 0, 0, 0, 0, loc),
-				Amount:            5,
+				Amount:            500,
 				CodeType:          "BARCODE",
+				FreeField: &utils.FreeField{
+					Agency:         "7197",
+					Account:        "27348",
+					AccountDigit:   "0",
+					Wallet:         "109",
+					OurNumber:      "26646729",
+					OurNumberDigit: "9",
+				},
 			},
 		},
 		{"73994000000000000000000000001223329012613034",
@@ -196,8 +240,16 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       6,
 				GeneralCheckDigit: 8,
 				DueDate:           time.Date(2024, 12, 5, 0, 0, 0, 0, loc),
-				Amount:            845.36,
+				Amount:            84536,
 				CodeType:          "BARCODE",
+				FreeField: &utils.FreeField{
+					Agency:         "0228",
+					Wallet:         "1",
+					Agreement:      "00282",
+					OurNumber:      "21577703",
+					OurNumberDigit: "7",
+					Extra:          map[string]string{"CollectionType": "1", "FreeFieldDigit": "5", "Post": "00"},
+				},
 			},
 		},
 		{input: "123456789",
@@ -215,10 +267,26 @@ func TestValues_Parse(t *testing.T) {
 				CheckDigit3:       2,
 				GeneralCheckDigit: 8,
 				DueDate:           time.Date(2018, 9, 17, 0, 0, 0, 0, loc),
-				Amount:            37.2,
+				Amount:            3720,
 				CodeType:          "DIGITABLE_LINE",
 			},
 		},
+		{input: "99992990600000123451234567890123456789012345",
+			want: &utils.Boleto{IssuerBankCode: "999",
+				UnknownIssuer:     true,
+				Currency:          9,
+				IssuerReserved1:   "12345",
+				CheckDigit1:       9,
+				IssuerReserved2:   "6789012345",
+				CheckDigit2:       7,
+				IssuerReserved3:   "6789012345",
+				CheckDigit3:       7,
+				GeneralCheckDigit: 2,
+				DueDate:           time.Date(2024, 11, 20, 0, 0, 0, 0, loc),
+				Amount:            12345,
+				CodeType:          "BARCODE",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -264,3 +332,310 @@ func TestValues_GetBoletoType(t *testing.T) {
 		}
 	}
 }
+
+func TestValues_ParseArrecadacao(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *utils.Arrecadacao
+	}{
+		{"826700000035 645607980002 010002351038 822024116714",
+			&utils.Arrecadacao{ProductID: "8",
+				Segment:           utils.Sanitation,
+				SegmentCode:       "2",
+				ValueIdentifier:   6,
+				GeneralCheckDigit: 7,
+				Amount:            36456,
+				CompanyID:         "0798",
+				FreeField:         "0000100023510382202411671",
+				CheckDigit1:       5,
+				CheckDigit2:       2,
+				CheckDigit3:       8,
+				CheckDigit4:       4,
+				CodeType:          "DIGITABLE_LINE",
+			},
+		},
+		{"82670000003645607980000100023510382202411671",
+			&utils.Arrecadacao{ProductID: "8",
+				Segment:           utils.Sanitation,
+				SegmentCode:       "2",
+				ValueIdentifier:   6,
+				GeneralCheckDigit: 7,
+				Amount:            36456,
+				CompanyID:         "0798",
+				FreeField:         "0000100023510382202411671",
+				CheckDigit1:       5,
+				CheckDigit2:       2,
+				CheckDigit3:       8,
+				CheckDigit4:       4,
+				CodeType:          "BARCODE",
+			},
+		},
+		{"85860000000 4 83740385242 0 43070124241 5 85141630306 0",
+			&utils.Arrecadacao{ProductID: "8",
+				Segment:           utils.GovernmentAgencies,
+				SegmentCode:       "5",
+				ValueIdentifier:   8,
+				GeneralCheckDigit: 6,
+				Amount:            8374,
+				CompanyID:         "0385",
+				FreeField:         "2424307012424185141630306",
+				CheckDigit1:       4,
+				CheckDigit2:       0,
+				CheckDigit3:       5,
+				CheckDigit4:       0,
+				CodeType:          "DIGITABLE_LINE",
+			},
+		},
+		{"85860000000837403852424307012424185141630306",
+			&utils.Arrecadacao{ProductID: "8",
+				Segment:           utils.GovernmentAgencies,
+				SegmentCode:       "5",
+				ValueIdentifier:   8,
+				GeneralCheckDigit: 6,
+				Amount:            8374,
+				CompanyID:         "0385",
+				FreeField:         "2424307012424185141630306",
+				CheckDigit1:       4,
+				CheckDigit2:       0,
+				CheckDigit3:       5,
+				CheckDigit4:       0,
+				CodeType:          "BARCODE",
+			},
+		},
+		{"846800000008550000791008011193989719924101544345",
+			&utils.Arrecadacao{ProductID: "8",
+				Segment:           utils.Telecommunications,
+				SegmentCode:       "4",
+				ValueIdentifier:   6,
+				GeneralCheckDigit: 8,
+				Amount:            5500,
+				CompanyID:         "0079",
+				FreeField:         "1000111939897192410154434",
+				CheckDigit1:       8,
+				CheckDigit2:       8,
+				CheckDigit3:       9,
+				CheckDigit4:       5,
+				CodeType:          "DIGITABLE_LINE",
+			},
+		},
+		{input: "34191.75124 34567.871230 41234.560005 8 92850000026035",
+			want: nil,
+		},
+		{input: "85560000000 4 83740385242 0 43070124241 5 85141630306 0",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		v, _ := ParseArrecadacao(tt.input)
+
+		if diff := cmp.Diff(tt.want, v); diff != "" {
+			t.Errorf("ParseArrecadacao(%v) mismatch:\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestValues_ParseErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		parse  func(string) error
+		want   error
+		field  string
+		offset int
+	}{
+		{"123456789",
+			func(code string) error { _, err := Parse(code); return err },
+			ErrUnknownCode, "Length", -1,
+		},
+		{"826700000035 645607980002 010002351038 822024116714",
+			func(code string) error { _, err := Parse(code); return err },
+			ErrArrecadacao, "ProductID", 0,
+		},
+		{"34191.75124 34567.871230 41234.560005 7 92850000026035 0",
+			func(code string) error { _, err := ParseArrecadacao(code); return err },
+			ErrNotArrecadacao, "ProductID", 0,
+		},
+		{" 85560000000 4 83740385242 0 43070124241 5 85141630306 0",
+			func(code string) error { _, err := ParseArrecadacao(code); return err },
+			ErrInvalidValueIdentifier, "ValueIdentifier", 3,
+		},
+		{"12345",
+			func(code string) error { _, err := ParseArrecadacao(code); return err },
+			ErrUnknownCode, "Length", -1,
+		},
+	}
+
+	for _, tt := range tests {
+		err := tt.parse(tt.input)
+
+		if !errors.Is(err, tt.want) {
+			t.Fatalf("parse(%q) = %v, want %v", tt.input, err, tt.want)
+		}
+
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("parse(%q) = %T, want *ParseError", tt.input, err)
+		}
+
+		if parseErr.Field != tt.field || parseErr.Offset != tt.offset || parseErr.Input != tt.input {
+			t.Errorf("parse(%q) = %+v, want field %s at offset %d", tt.input, parseErr, tt.field, tt.offset)
+		}
+	}
+}
+
+func TestValues_ParseErrorMessage(t *testing.T) {
+	err := &ParseError{Input: "8556", Field: "ValueIdentifier", Offset: 2, Err: ErrInvalidValueIdentifier}
+	want := `parse "8556": ValueIdentifier at offset 2: invalid value identifier`
+
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestValues_InputOffset(t *testing.T) {
+	tests := []struct {
+		codeType utils.BoletoCodeType
+		index    int
+		want     int
+	}{
+		{DigitableLine, 33, 33},
+		{Barcode, 33, 5},
+		{Barcode, 37, 9},
+		{Barcode, 3, 3},
+		{Barcode, 9, -1},
+		{Barcode, 12, 26},
+	}
+
+	for _, tt := range tests {
+		if got := inputIndex(tt.codeType, tt.index); got != tt.want {
+			t.Errorf("inputIndex(%s, %d) = %d, want %d", tt.codeType, tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestValues_ParseWithOptions(t *testing.T) {
+	loc, _ := time.LoadLocation("UTC")
+	resetDate, _ := time.Parse(BaseDateFormat, utils.FactorResetDate)
+
+	tests := []struct {
+		input     string
+		reference time.Time
+		want      time.Time
+	}{
+		{"34191.75124 34567.871230 41234.560005 7 10000000026035",
+			time.Date(2026, 10, 18, 0, 0, 0, 0, loc),
+			resetDate,
+		},
+		{"34191.75124 34567.871230 41234.560005 7 10000000026035",
+			time.Date(2001, 1, 1, 0, 0, 0, 0, loc),
+			time.Date(2000, 7, 3, 0, 0, 0, 0, loc),
+		},
+		{"34191.75124 34567.871230 41234.560005 7 99990000026035",
+			time.Date(2025, 3, 1, 0, 0, 0, 0, loc),
+			time.Date(2025, 2, 21, 0, 0, 0, 0, loc),
+		},
+		{"34191.75124 34567.871230 41234.560005 7 15000000026035",
+			time.Date(2026, 10, 18, 0, 0, 0, 0, loc),
+			time.Date(2026, 7, 7, 0, 0, 0, 0, loc),
+		},
+		{"34191.75124 34567.871230 41234.560005 7 69050000026035",
+			time.Date(2026, 10, 18, 0, 0, 0, 0, loc),
+			time.Date(2016, 9, 2, 0, 0, 0, 0, loc),
+		},
+		{"34191.75124 34567.871230 41234.560005 7 69050000026035",
+			time.Date(2040, 1, 1, 0, 0, 0, 0, loc),
+			time.Date(2041, 4, 24, 0, 0, 0, 0, loc),
+		},
+		{"34191.75124 34567.871230 41234.560005 7 15000000026035",
+			time.Date(1990, 1, 1, 0, 0, 0, 0, loc),
+			time.Date(2001, 11, 15, 0, 0, 0, 0, loc),
+		},
+		{"34191.75124 34567.871230 41234.560005 7 00000000026035",
+			time.Date(2026, 10, 18, 0, 0, 0, 0, loc),
+			time.Date(1997, 10, 7, 0, 0, 0, 0, loc),
+		},
+		{"34191.75124 34567.871230 41234.560005 7 55000000026035",
+			time.Time{},
+			time.Date(2037, 6, 19, 0, 0, 0, 0, loc),
+		},
+		{"34191.75124 34567.871230 41234.560005 7 55010000026035",
+			time.Time{},
+			time.Date(2012, 10, 29, 0, 0, 0, 0, loc),
+		},
+		{"34191.75124 34567.871230 41234.560005 7 69050000026035",
+			time.Time{},
+			time.Date(2016, 9, 2, 0, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		boleto, err := ParseWithOptions(tt.input, Options{ReferenceDate: tt.reference})
+
+		if err != nil {
+			t.Fatalf("ParseWithOptions(%v) returned %v", tt.input, err)
+		}
+
+		if !boleto.DueDate.Equal(tt.want) {
+			t.Errorf("ParseWithOptions(%v, %s) due date = %s, want %s", tt.input, tt.reference.Format("2006-01-02"), boleto.DueDate.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestValues_ConvertDigitableLineToBarcode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+		field string
+	}{
+		{input: "34191.09263 64672.997190 72734.800005 1 99060000000500",
+			want: "34191990600000005001092664672997197273480000",
+		},
+		{input: "23793.38128 60005.963347 21000.063301 1 74640000116037",
+			want: "23791746400001160373381260005963342100006330",
+		},
+		{input: "82670000003-5 64560798000-2 01000235103-8 82202411671-4",
+			want: "82670000003645607980000100023510382202411671",
+		},
+		{input: "85860000000 4 83740385242 0 43070124241 5 85141630306 0",
+			want: "85860000000837403852424307012424185141630306",
+		},
+		{input: "34191.09263 64672.997191 72734.800005 1 99060000000500",
+			err:   ErrInvalidCheckDigit,
+			field: "CheckDigit2",
+		},
+		{input: "34191.09263 64672.997190 72734.800005 2 99060000000500",
+			err:   ErrInvalidCheckDigit,
+			field: "GeneralCheckDigit",
+		},
+		{input: "82670000003-5 64560798000-2 01000235103-8 82202411671-5",
+			err:   ErrInvalidCheckDigit,
+			field: "CheckDigit4",
+		},
+		{input: "82570000003-5 64560798000-2 01000235103-8 82202411671-4",
+			err:   ErrInvalidValueIdentifier,
+			field: "ValueIdentifier",
+		},
+		{input: "3419",
+			err:   ErrUnknownCode,
+			field: "Length",
+		},
+	}
+
+	for _, tt := range tests {
+		barcode, err := ConvertDigitableLineToBarcode(tt.input)
+
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("ConvertDigitableLineToBarcode(%v) = %v, want %v", tt.input, err, tt.err)
+		}
+
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) && parseErr.Field != tt.field {
+			t.Errorf("ConvertDigitableLineToBarcode(%v) failed on %s, want %s", tt.input, parseErr.Field, tt.field)
+		}
+
+		if barcode != tt.want {
+			t.Errorf("ConvertDigitableLineToBarcode(%v) = %v, want %v", tt.input, barcode, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package pix
+
+import (
+	"fmt"
+	"github.com/fonini/go-boleto-utils/generator"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+)
+
+// Hybrid is a boleto híbrido: a bank boleto that can also be paid through the PIX BR Code
+// printed with it
+type Hybrid struct {
+	Boleto *utils.Boleto
+	Pix    *Payload
+	BRCode string
+}
+
+// HybridResult holds the codes of a generated boleto híbrido
+type HybridResult struct {
+	generator.Result
+	BRCode string
+}
+
+// ParseHybrid parses the barcode or digitable line of a boleto and the BR Code printed with
+// it, checking that both charge the same amount. A BR Code without an amount matches any boleto.
+func ParseHybrid(code string, brCode string) (*Hybrid, error) {
+	boleto, err := parser.Parse(code)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := Parse(brCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkAmount(boleto.Amount, payload.Amount); err != nil {
+		return nil, err
+	}
+
+	return &Hybrid{Boleto: boleto, Pix: payload, BRCode: brCode}, nil
+}
+
+// GenerateHybrid generates a boleto and the BR Code of payload. A payload without an amount
+// takes the amount of the boleto; one with an amount must match it.
+func GenerateHybrid(request generator.Request, payload Payload) (*HybridResult, error) {
+	if payload.Amount == 0 {
+		payload.Amount = request.Amount
+	}
+
+	if err := checkAmount(request.Amount, payload.Amount); err != nil {
+		return nil, err
+	}
+
+	result, err := generator.Generate(request)
+	if err != nil {
+		return nil, err
+	}
+
+	brCode, err := payload.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &HybridResult{Result: *result, BRCode: brCode}, nil
+}
+
+func checkAmount(boleto utils.Money, pix utils.Money) error {
+	if pix != 0 && pix != boleto {
+		return fmt.Errorf("%w: boleto %s, PIX %s", ErrAmountMismatch, boleto, pix)
+	}
+
+	return nil
+}
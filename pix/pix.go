@@ -0,0 +1,307 @@
+package pix
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/qrcode"
+	"github.com/fonini/go-boleto-utils/utils"
+	"strings"
+)
+
+// IDs of the BR Code fields
+const (
+	IDPayloadFormatIndicator     = "00"
+	IDPointOfInitiationMethod    = "01"
+	IDMerchantAccountInformation = "26"
+	IDMerchantCategoryCode       = "52"
+	IDTransactionCurrency        = "53"
+	IDTransactionAmount          = "54"
+	IDCountryCode                = "58"
+	IDMerchantName               = "59"
+	IDMerchantCity               = "60"
+	IDPostalCode                 = "61"
+	IDAdditionalDataField        = "62"
+	IDCRC                        = "63"
+)
+
+// IDs of the fields of the merchant account information and additional data field templates
+const (
+	IDGUI         = "00"
+	IDKey         = "01"
+	IDDescription = "02"
+	IDURL         = "25"
+	IDTxID        = "05"
+)
+
+const (
+	// GUI identifies the PIX arrangement in the merchant account information
+	GUI = "br.gov.bcb.pix"
+	// PayloadFormat is the only payload format indicator
+	PayloadFormat = "01"
+	// CurrencyBRL is the ISO 4217 numeric code of the Brazilian real
+	CurrencyBRL = "986"
+	// CountryBR is the ISO 3166-1 code of Brazil
+	CountryBR = "BR"
+	// NoTxID is the transaction ID of payloads not tied to a transaction
+	NoTxID = "***"
+
+	// Points of initiation
+	Reusable  = "11"
+	SingleUse = "12"
+)
+
+var (
+	ErrInvalidTLV     = errors.New("invalid BR Code TLV data")
+	ErrInvalidCRC     = errors.New("invalid BR Code CRC")
+	ErrMissingField   = errors.New("missing BR Code field")
+	ErrInvalidField   = errors.New("invalid BR Code field")
+	ErrAmountMismatch = errors.New("PIX amount does not match the boleto amount")
+)
+
+// Payload holds the fields of a PIX BR Code. A static payload carries the receiver's PIX
+// key, a dynamic one the URL of the charge at the receiver's PSP. A zero Amount lets the
+// payer type the amount.
+type Payload struct {
+	PointOfInitiationMethod string
+	Key                     string
+	Description             string
+	URL                     string
+	MerchantCategoryCode    string
+	Amount                  utils.Money
+	MerchantName            string
+	MerchantCity            string
+	PostalCode              string
+	TxID                    string
+}
+
+// Parse parses and validates a BR Code: its TLV structure, the mandatory fields, the PIX
+// merchant account information and the CRC
+func Parse(brCode string) (*Payload, error) {
+	fields, err := ParseTLV(brCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) == 0 || fields[0].ID != IDPayloadFormatIndicator {
+		return nil, fmt.Errorf("%w: %s must be the first field", ErrInvalidField, IDPayloadFormatIndicator)
+	}
+
+	last := fields[len(fields)-1]
+	if last.ID != IDCRC || len(last.Value) != 4 {
+		return nil, fmt.Errorf("%w: %s must be the last field", ErrInvalidField, IDCRC)
+	}
+
+	if crc := fmt.Sprintf("%04X", CRC16(brCode[:len(brCode)-4])); !strings.EqualFold(crc, last.Value) {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrInvalidCRC, crc, last.Value)
+	}
+
+	values := map[string]string{}
+	for _, field := range fields {
+		if _, ok := values[field.ID]; !ok {
+			values[field.ID] = field.Value
+		}
+	}
+
+	for _, id := range []string{IDMerchantAccountInformation, IDMerchantCategoryCode, IDTransactionCurrency, IDCountryCode, IDMerchantName, IDMerchantCity, IDAdditionalDataField} {
+		if _, ok := values[id]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingField, id)
+		}
+	}
+
+	payload := &Payload{
+		PointOfInitiationMethod: values[IDPointOfInitiationMethod],
+		MerchantCategoryCode:    values[IDMerchantCategoryCode],
+		MerchantName:            values[IDMerchantName],
+		MerchantCity:            values[IDMerchantCity],
+		PostalCode:              values[IDPostalCode],
+	}
+
+	account, err := ParseTLV(values[IDMerchantAccountInformation])
+	if err != nil {
+		return nil, err
+	}
+
+	if gui, _ := lookup(account, IDGUI); !strings.EqualFold(gui, GUI) {
+		return nil, fmt.Errorf("%w: %s is not a PIX merchant account", ErrInvalidField, IDMerchantAccountInformation)
+	}
+
+	payload.Key, _ = lookup(account, IDKey)
+	payload.Description, _ = lookup(account, IDDescription)
+	payload.URL, _ = lookup(account, IDURL)
+
+	additional, err := ParseTLV(values[IDAdditionalDataField])
+	if err != nil {
+		return nil, err
+	}
+
+	txID, ok := lookup(additional, IDTxID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s.%s", ErrMissingField, IDAdditionalDataField, IDTxID)
+	}
+	payload.TxID = txID
+
+	if amount, ok := values[IDTransactionAmount]; ok {
+		payload.Amount, err = parseAmount(amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if values[IDPayloadFormatIndicator] != PayloadFormat {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidField, IDPayloadFormatIndicator)
+	}
+
+	if values[IDTransactionCurrency] != CurrencyBRL {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidField, IDTransactionCurrency)
+	}
+
+	if values[IDCountryCode] != CountryBR {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidField, IDCountryCode)
+	}
+
+	if err := payload.validate(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// Encode writes the payload as a BR Code, ending with its CRC
+func (p *Payload) Encode() (string, error) {
+	payload := *p
+
+	if payload.MerchantCategoryCode == "" {
+		payload.MerchantCategoryCode = "0000"
+	}
+
+	if payload.TxID == "" {
+		payload.TxID = NoTxID
+	}
+
+	if err := payload.validate(); err != nil {
+		return "", err
+	}
+
+	accountFields := []Field{{IDGUI, GUI}}
+	if payload.Key != "" {
+		accountFields = append(accountFields, Field{IDKey, payload.Key})
+	}
+	if payload.Description != "" {
+		accountFields = append(accountFields, Field{IDDescription, payload.Description})
+	}
+	if payload.URL != "" {
+		accountFields = append(accountFields, Field{IDURL, payload.URL})
+	}
+
+	account, err := EncodeTLV(accountFields)
+	if err != nil {
+		return "", err
+	}
+
+	additional, err := EncodeTLV([]Field{{IDTxID, payload.TxID}})
+	if err != nil {
+		return "", err
+	}
+
+	fields := []Field{{IDPayloadFormatIndicator, PayloadFormat}}
+	if payload.PointOfInitiationMethod != "" {
+		fields = append(fields, Field{IDPointOfInitiationMethod, payload.PointOfInitiationMethod})
+	}
+
+	fields = append(fields,
+		Field{IDMerchantAccountInformation, account},
+		Field{IDMerchantCategoryCode, payload.MerchantCategoryCode},
+		Field{IDTransactionCurrency, CurrencyBRL},
+	)
+
+	if payload.Amount != 0 {
+		fields = append(fields, Field{IDTransactionAmount, payload.Amount.Decimal()})
+	}
+
+	fields = append(fields,
+		Field{IDCountryCode, CountryBR},
+		Field{IDMerchantName, payload.MerchantName},
+		Field{IDMerchantCity, payload.MerchantCity},
+	)
+
+	if payload.PostalCode != "" {
+		fields = append(fields, Field{IDPostalCode, payload.PostalCode})
+	}
+
+	fields = append(fields, Field{IDAdditionalDataField, additional})
+
+	data, err := EncodeTLV(fields)
+	if err != nil {
+		return "", err
+	}
+
+	data += IDCRC + "04"
+
+	return data + fmt.Sprintf("%04X", CRC16(data)), nil
+}
+
+// validate checks the field sizes and characters the BR Code specification sets
+func (p *Payload) validate() error {
+	switch {
+	case p.PointOfInitiationMethod != "" && p.PointOfInitiationMethod != Reusable && p.PointOfInitiationMethod != SingleUse:
+		return fmt.Errorf("%w: %s", ErrInvalidField, IDPointOfInitiationMethod)
+	case p.Key == "" && p.URL == "":
+		return fmt.Errorf("%w: %s.%s", ErrMissingField, IDMerchantAccountInformation, IDKey)
+	case len(p.Key) > 77 || !isASCII(p.Key) || !isASCII(p.Description) || !isASCII(p.URL):
+		return fmt.Errorf("%w: %s", ErrInvalidField, IDMerchantAccountInformation)
+	case len(p.MerchantCategoryCode) != 4 || !isDigits(p.MerchantCategoryCode):
+		return fmt.Errorf("%w: %s", ErrInvalidField, IDMerchantCategoryCode)
+	case p.Amount < 0 || len(p.Amount.Decimal()) > 13:
+		return fmt.Errorf("%w: %s", ErrInvalidField, IDTransactionAmount)
+	case p.MerchantName == "" || len(p.MerchantName) > 25 || !isASCII(p.MerchantName):
+		return fmt.Errorf("%w: %s", ErrInvalidField, IDMerchantName)
+	case p.MerchantCity == "" || len(p.MerchantCity) > 15 || !isASCII(p.MerchantCity):
+		return fmt.Errorf("%w: %s", ErrInvalidField, IDMerchantCity)
+	case len(p.PostalCode) > 99 || !isASCII(p.PostalCode):
+		return fmt.Errorf("%w: %s", ErrInvalidField, IDPostalCode)
+	case p.TxID != NoTxID && (p.TxID == "" || len(p.TxID) > 25 || !isAlphanumeric(p.TxID)):
+		return fmt.Errorf("%w: %s.%s", ErrInvalidField, IDAdditionalDataField, IDTxID)
+	}
+
+	return nil
+}
+
+// parseAmount reads a transaction amount, written with a dot and up to two decimal places
+func parseAmount(amount string) (utils.Money, error) {
+	if amount == "" || len(amount) > 13 || strings.Trim(amount, "0123456789.") != "" {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidField, IDTransactionAmount)
+	}
+
+	money, err := utils.ParseMoney(amount)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidField, IDTransactionAmount)
+	}
+
+	return money, nil
+}
+
+// QRCode encodes a BR Code as a QR code with the medium error correction level
+func QRCode(brCode string) (*qrcode.Code, error) {
+	return qrcode.Encode([]byte(brCode), qrcode.Medium)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isAlphanumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,239 @@
+package pix
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/generator"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// staticBRCode is the static BR Code example of the Banco Central PIX manual
+const staticBRCode = "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+
+// withCRC appends the CRC field to data
+func withCRC(data string) string {
+	data += "6304"
+	return data + fmt.Sprintf("%04X", CRC16(data))
+}
+
+func TestValues_CRC16(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint16
+	}{
+		{"123456789", 0x29b1},
+		{staticBRCode[:len(staticBRCode)-4], 0x1d3d},
+	}
+
+	for _, tt := range tests {
+		if got := CRC16(tt.input); got != tt.want {
+			t.Errorf("CRC16(%v) = %04X, want %04X", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestValues_ParseTLV(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Field
+		err   error
+	}{
+		{"000201260800041234", []Field{{"00", "01"}, {"26", "00041234"}}, nil},
+		{"", nil, nil},
+		{"0002010", nil, ErrInvalidTLV},
+		{"00050123", nil, ErrInvalidTLV},
+		{"0A0201", nil, ErrInvalidTLV},
+		{"00+101", nil, ErrInvalidTLV},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTLV(tt.input)
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseTLV(%v) returned error %v, want %v", tt.input, err, tt.err)
+			continue
+		}
+
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("ParseTLV(%v) mismatch (-want +got):\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestValues_Parse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  *Payload
+		err   error
+	}{
+		{staticBRCode,
+			&Payload{
+				Key:                  "123e4567-e12b-12d1-a456-426655440000",
+				MerchantCategoryCode: "0000",
+				MerchantName:         "Fulano de Tal",
+				MerchantCity:         "BRASILIA",
+				TxID:                 "***",
+			},
+			nil,
+		},
+		{"00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3E",
+			nil,
+			ErrInvalidCRC,
+		},
+		{withCRC("00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA"),
+			nil,
+			ErrMissingField,
+		},
+		{withCRC("00020126330014br.gov.bcb.pix0111fulano@test5204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***"),
+			&Payload{
+				Key:                  "fulano@test",
+				MerchantCategoryCode: "0000",
+				MerchantName:         "Fulano de Tal",
+				MerchantCity:         "BRASILIA",
+				TxID:                 "***",
+			},
+			nil,
+		},
+		{withCRC("00020126330014br.gov.bcb.xyz0111fulano@test5204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***"),
+			nil,
+			ErrInvalidField,
+		},
+		{withCRC("00020126330014br.gov.bcb.pix0111fulano@test52040000530398654041,005802BR5913Fulano de Tal6008BRASILIA62070503***"),
+			nil,
+			ErrInvalidField,
+		},
+		{"000201",
+			nil,
+			ErrInvalidField,
+		},
+		{"000201260",
+			nil,
+			ErrInvalidTLV,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%v) returned error %v, want %v", tt.input, err, tt.err)
+			continue
+		}
+
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("Parse(%v) mismatch (-want +got):\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestValues_Encode(t *testing.T) {
+	tests := []struct {
+		input Payload
+		err   error
+	}{
+		{Payload{Key: "123e4567-e12b-12d1-a456-426655440000", MerchantName: "Fulano de Tal", MerchantCity: "BRASILIA"}, nil},
+		{Payload{
+			PointOfInitiationMethod: SingleUse,
+			URL:                     "pix.example.com/qr/v2/9d36b84fc70b478fb95c12729b90ca25",
+			MerchantCategoryCode:    "5812",
+			Amount:                  116037,
+			MerchantName:            "Padaria Pao Quente",
+			MerchantCity:            "SAO PAULO",
+			PostalCode:              "01310100",
+			TxID:                    "BOLETO123",
+		}, nil},
+		{Payload{MerchantName: "Fulano de Tal", MerchantCity: "BRASILIA"}, ErrMissingField},
+		{Payload{Key: "fulano@example.com", MerchantName: "Fulano de Tal com um nome muito longo", MerchantCity: "BRASILIA"}, ErrInvalidField},
+		{Payload{Key: "fulano@example.com", MerchantName: "Padaria Pão Quente", MerchantCity: "BRASILIA"}, ErrInvalidField},
+		{Payload{Key: "fulano@example.com", MerchantName: "Fulano de Tal", MerchantCity: "BRASILIA", TxID: "boleto-123"}, ErrInvalidField},
+		{Payload{Key: "fulano@example.com", MerchantName: "Fulano de Tal", MerchantCity: "BRASILIA", Amount: -1}, ErrInvalidField},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.input.Encode()
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Encode(%+v) returned error %v, want %v", tt.input, err, tt.err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		parsed, err := Parse(got)
+		if err != nil {
+			t.Errorf("Parse(Encode(%+v)) returned %v", tt.input, err)
+			continue
+		}
+
+		want := tt.input
+		if want.MerchantCategoryCode == "" {
+			want.MerchantCategoryCode = "0000"
+		}
+		if want.TxID == "" {
+			want.TxID = NoTxID
+		}
+
+		if diff := cmp.Diff(&want, parsed); diff != "" {
+			t.Errorf("Parse(Encode(%+v)) mismatch (-want +got):\n%s", tt.input, diff)
+		}
+	}
+
+	got, _ := (&Payload{Key: "123e4567-e12b-12d1-a456-426655440000", MerchantName: "Fulano de Tal", MerchantCity: "BRASILIA"}).Encode()
+	if got != staticBRCode {
+		t.Errorf("Encode() = %v, want %v", got, staticBRCode)
+	}
+}
+
+func TestValues_Hybrid(t *testing.T) {
+	request := generator.Request{
+		BankCode:  "341",
+		Currency:  generator.RealCurrency,
+		DueDate:   time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC),
+		Amount:    500,
+		FreeField: "1092664672997197273480000",
+	}
+	payload := Payload{Key: "fulano@example.com", MerchantName: "Fulano de Tal", MerchantCity: "BRASILIA", TxID: "NF1234"}
+
+	result, err := GenerateHybrid(request, payload)
+	if err != nil {
+		t.Fatalf("GenerateHybrid returned %v", err)
+	}
+
+	if result.Barcode != "34191990600000005001092664672997197273480000" {
+		t.Errorf("GenerateHybrid barcode = %v", result.Barcode)
+	}
+
+	hybrid, err := ParseHybrid(result.DigitableLine, result.BRCode)
+	if err != nil {
+		t.Fatalf("ParseHybrid returned %v", err)
+	}
+
+	if hybrid.Boleto.IssuerBankCode != "341" || hybrid.Pix.Amount != 500 || hybrid.Pix.TxID != "NF1234" {
+		t.Errorf("ParseHybrid returned boleto %+v and payload %+v", hybrid.Boleto, hybrid.Pix)
+	}
+
+	payload.Amount = 501
+	if _, err := GenerateHybrid(request, payload); !errors.Is(err, ErrAmountMismatch) {
+		t.Errorf("GenerateHybrid with a different amount returned %v, want %v", err, ErrAmountMismatch)
+	}
+
+	mismatched, _ := payload.Encode()
+	if _, err := ParseHybrid(result.Barcode, mismatched); !errors.Is(err, ErrAmountMismatch) {
+		t.Errorf("ParseHybrid with a different amount returned %v, want %v", err, ErrAmountMismatch)
+	}
+
+	payload.Amount = 0
+	open, _ := payload.Encode()
+	if _, err := ParseHybrid(result.Barcode, open); err != nil {
+		t.Errorf("ParseHybrid with no PIX amount returned %v", err)
+	}
+
+	if code, err := QRCode(result.BRCode); err != nil || code.Size < 21 {
+		t.Errorf("QRCode returned %v", err)
+	}
+}
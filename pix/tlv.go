@@ -0,0 +1,99 @@
+package pix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Field is an EMV data object: a 2-digit ID and its value. Template fields, such as the
+// merchant account information, hold more fields in their value.
+type Field struct {
+	ID    string
+	Value string
+}
+
+// ParseTLV splits data into its fields, each written as the ID, the 2-digit length of the
+// value and the value
+func ParseTLV(data string) ([]Field, error) {
+	var fields []Field
+
+	for offset := 0; offset < len(data); {
+		if offset+4 > len(data) {
+			return nil, fmt.Errorf("%w: truncated field at offset %d", ErrInvalidTLV, offset)
+		}
+
+		id := data[offset : offset+2]
+		length, err := strconv.Atoi(data[offset+2 : offset+4])
+
+		if !isDigits(id) || err != nil || !isDigits(data[offset+2:offset+4]) {
+			return nil, fmt.Errorf("%w: invalid field header %q at offset %d", ErrInvalidTLV, data[offset:offset+4], offset)
+		}
+
+		if offset+4+length > len(data) {
+			return nil, fmt.Errorf("%w: field %s at offset %d is longer than the data", ErrInvalidTLV, id, offset)
+		}
+
+		fields = append(fields, Field{ID: id, Value: data[offset+4 : offset+4+length]})
+		offset += 4 + length
+	}
+
+	return fields, nil
+}
+
+// EncodeTLV writes fields in the TLV format read by ParseTLV
+func EncodeTLV(fields []Field) (string, error) {
+	var data strings.Builder
+
+	for _, field := range fields {
+		if len(field.ID) != 2 || !isDigits(field.ID) || len(field.Value) > 99 {
+			return "", fmt.Errorf("%w: field %s", ErrInvalidTLV, field.ID)
+		}
+
+		fmt.Fprintf(&data, "%s%02d%s", field.ID, len(field.Value), field.Value)
+	}
+
+	return data.String(), nil
+}
+
+// lookup returns the value of the first field with the given ID
+func lookup(fields []Field, id string) (string, bool) {
+	for _, field := range fields {
+		if field.ID == id {
+			return field.Value, true
+		}
+	}
+
+	return "", false
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CRC16 computes the CRC-16/CCITT-FALSE checksum of a BR Code: polynomial 0x1021 and
+// initial value 0xFFFF, over every character up to and including the ID and length of
+// the CRC field, "6304"
+func CRC16(data string) uint16 {
+	crc := uint16(0xffff)
+
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
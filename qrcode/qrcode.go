@@ -0,0 +1,538 @@
+package qrcode
+
+import (
+	"errors"
+)
+
+// Level is the error correction level of a QR code
+type Level int
+
+const (
+	Low      Level = iota // recovers about 7% of the codewords
+	Medium                // recovers about 15% of the codewords
+	Quartile              // recovers about 25% of the codewords
+	High                  // recovers about 30% of the codewords
+)
+
+var (
+	ErrTooLong      = errors.New("data too long for a QR code")
+	ErrInvalidLevel = errors.New("invalid error correction level")
+)
+
+// formatLevels holds the two bits that identify each Level in the format information
+var formatLevels = [4]int{1, 0, 3, 2}
+
+// eccCodewordsPerBlock and eccBlocks hold, per Level and version, the error correction
+// codewords of each block and the number of blocks. Index 0 is unused.
+var eccCodewordsPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var eccBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// Code is an encoded QR code: a square of dark and light modules
+type Code struct {
+	Version int
+	Level   Level
+	Size    int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Dark reports whether the module at column x and row y is dark. Modules outside the code are light.
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// Encode encodes data in byte mode with the smallest version that fits it at the given
+// error correction level, choosing the mask with the lowest penalty
+func Encode(data []byte, level Level) (*Code, error) {
+	if level < Low || level > High {
+		return nil, ErrInvalidLevel
+	}
+
+	version := 1
+	for ; version <= 40; version++ {
+		if dataBits(data, version) <= dataCodewords(version, level)*8 {
+			break
+		}
+	}
+
+	if version > 40 {
+		return nil, ErrTooLong
+	}
+
+	c := newCode(version, level)
+	c.drawCodewords(c.addErrorCorrection(c.dataCodewords(data)))
+
+	best, bestPenalty := 0, -1
+
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(mask)
+
+		if penalty := c.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+
+		// masking twice restores the modules
+		c.applyMask(mask)
+	}
+
+	c.applyMask(best)
+	c.drawFormatBits(best)
+
+	return c, nil
+}
+
+// dataBits is the length of data in byte mode: mode indicator, character count and bytes
+func dataBits(data []byte, version int) int {
+	return 4 + charCountBits(version) + 8*len(data)
+}
+
+func charCountBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+
+	return 16
+}
+
+// rawDataModules is the number of modules of a version left for data and error correction
+// codewords once the function patterns are drawn
+func rawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+
+	if version >= 2 {
+		alignments := version/7 + 2
+		result -= (25*alignments-10)*alignments - 55
+
+		if version >= 7 {
+			result -= 36
+		}
+	}
+
+	return result
+}
+
+// dataCodewords is the number of data codewords of a version at a level
+func dataCodewords(version int, level Level) int {
+	return rawDataModules(version)/8 - eccCodewordsPerBlock[level][version]*eccBlocks[level][version]
+}
+
+func newCode(version int, level Level) *Code {
+	size := version*4 + 17
+
+	c := &Code{
+		Version:    version,
+		Level:      level,
+		Size:       size,
+		modules:    make([][]bool, size),
+		isFunction: make([][]bool, size),
+	}
+
+	for i := range c.modules {
+		c.modules[i] = make([]bool, size)
+		c.isFunction[i] = make([]bool, size)
+	}
+
+	c.drawFunctionPatterns()
+
+	return c
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunction[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	positions := alignmentPositions(c.Version)
+	last := len(positions) - 1
+
+	for i, x := range positions {
+		for j, y := range positions {
+			// skip the three corners taken by finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+
+			c.drawAlignment(x, y)
+		}
+	}
+
+	// reserve the format information area, drawn again once the mask is chosen
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// drawFinder draws a finder pattern and its separator centered at x, y
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+
+			distance := max(abs(dx), abs(dy))
+			c.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions returns the row and column coordinates of the alignment pattern centers
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	alignments := version/7 + 2
+	step := (version*8 + alignments*3 + 5) / (alignments*4 - 4) * 2
+
+	positions := make([]int, alignments)
+	positions[0] = 6
+
+	for i, position := alignments-1, version*4+10; i >= 1; i, position = i-1, position-step {
+		positions[i] = position
+	}
+
+	return positions
+}
+
+// formatBits returns the 15 format information bits of a level and mask, BCH encoded and masked
+func formatBits(level Level, mask int) int {
+	data := formatLevels[level]<<3 | mask
+
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = remainder<<1 ^ (remainder>>9)*0x537
+	}
+
+	return (data<<10 | remainder) ^ 0x5412
+}
+
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(c.Level, mask)
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+
+	c.setFunction(8, c.Size-8, true)
+}
+
+// versionBits returns the 18 version information bits, BCH encoded
+func versionBits(version int) int {
+	remainder := version
+	for i := 0; i < 12; i++ {
+		remainder = remainder<<1 ^ (remainder>>11)*0x1f25
+	}
+
+	return version<<12 | remainder
+}
+
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+
+	bits := versionBits(c.Version)
+
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// dataCodewords encodes data in byte mode, terminated and padded to the capacity of the code
+func (c *Code) dataCodewords(data []byte) []byte {
+	capacity := dataCodewords(c.Version, c.Level) * 8
+
+	var bb bitBuffer
+
+	bb.append(0x4, 4)
+	bb.append(len(data), charCountBits(c.Version))
+
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	bb.append(0, min(4, capacity-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+
+	for pad := 0xec; len(bb) < capacity; pad ^= 0xec ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	return bb.bytes()
+}
+
+// addErrorCorrection splits the data codewords in blocks, appends the Reed-Solomon codewords
+// of each block and interleaves them
+func (c *Code) addErrorCorrection(data []byte) []byte {
+	blocks := eccBlocks[c.Level][c.Version]
+	eccLength := eccCodewordsPerBlock[c.Level][c.Version]
+	raw := rawDataModules(c.Version) / 8
+	shortBlocks := blocks - raw%blocks
+	shortLength := raw / blocks
+
+	divisor := reedSolomonDivisor(eccLength)
+	interleaved := make([][]byte, blocks)
+
+	for i, k := 0, 0; i < blocks; i++ {
+		length := shortLength - eccLength
+		if i >= shortBlocks {
+			length++
+		}
+
+		block := make([]byte, 0, shortLength+1)
+		block = append(block, data[k:k+length]...)
+		k += length
+
+		ecc := reedSolomonRemainder(block, divisor)
+
+		// short blocks get a placeholder so every block has the same length
+		if i < shortBlocks {
+			block = append(block, 0)
+		}
+
+		interleaved[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, raw)
+
+	for i := range interleaved[0] {
+		for j, block := range interleaved {
+			if i != shortLength-eccLength || j >= shortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	return result
+}
+
+// drawCodewords places the codewords in the zigzag order, two columns at a time from the
+// bottom right corner, skipping the function patterns
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		// the vertical timing pattern takes a whole column
+		if right == 6 {
+			right = 5
+		}
+
+		for vertical := 0; vertical < c.Size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vertical
+
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vertical
+				}
+
+				if c.isFunction[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+
+				c.modules[y][x] = bit(int(codewords[i>>3]), 7-i&7)
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by a mask pattern
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			var invert bool
+
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			if invert && !c.isFunction[y][x] {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the modules with the four rules of the specification: runs of a color,
+// 2x2 blocks of a color, finder-like patterns and the balance of dark and light modules
+func (c *Code) penalty() int {
+	penalty := 0
+	dark := 0
+
+	line := make([]bool, c.Size)
+
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < c.Size; i++ {
+			for j := 0; j < c.Size; j++ {
+				if vertical {
+					line[j] = c.modules[j][i]
+				} else {
+					line[j] = c.modules[i][j]
+				}
+			}
+
+			penalty += linePenalty(line)
+		}
+	}
+
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+
+			if x+1 < c.Size && y+1 < c.Size {
+				color := c.modules[y][x]
+				if c.modules[y][x+1] == color && c.modules[y+1][x] == color && c.modules[y+1][x+1] == color {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	penalty += abs(dark*100/total-50) / 5 * 10
+
+	return penalty
+}
+
+var finderLike = [2][11]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+func linePenalty(line []bool) int {
+	penalty := 0
+
+	for start := 0; start < len(line); {
+		end := start
+		for end < len(line) && line[end] == line[start] {
+			end++
+		}
+
+		if run := end - start; run >= 5 {
+			penalty += 3 + run - 5
+		}
+
+		start = end
+	}
+
+	for i := 0; i+11 <= len(line); i++ {
+		for _, pattern := range finderLike {
+			matches := true
+
+			for j, dark := range pattern {
+				if line[i+j] != dark {
+					matches = false
+					break
+				}
+			}
+
+			if matches {
+				penalty += 40
+			}
+		}
+	}
+
+	return penalty
+}
+
+// bitBuffer is a sequence of bits, most significant first
+type bitBuffer []bool
+
+func (bb *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*bb = append(*bb, bit(value, i))
+	}
+}
+
+func (bb bitBuffer) bytes() []byte {
+	result := make([]byte, len(bb)/8)
+
+	for i, set := range bb {
+		if set {
+			result[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+
+	return result
+}
+
+func bit(value, i int) bool {
+	return value>>i&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
@@ -0,0 +1,429 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValues_FormatBits(t *testing.T) {
+	// format information strings of the specification, for masks 0 to 7
+	tests := []struct {
+		level Level
+		want  []string
+	}{
+		{Low, []string{"111011111000100", "111001011110011", "111110110101010", "111100010011101", "110011000101111", "110001100011000", "110110001000001", "110100101110110"}},
+		{Medium, []string{"101010000010010", "101000100100101", "101111001111100", "101101101001011", "100010111111001", "100000011001110", "100111110010111", "100101010100000"}},
+	}
+
+	for _, tt := range tests {
+		for mask, want := range tt.want {
+			if got := formatBits(tt.level, mask); got != parseBits(want) {
+				t.Errorf("formatBits(%v, %d) = %015b, want %s", tt.level, mask, got, want)
+			}
+		}
+	}
+}
+
+func TestValues_VersionBits(t *testing.T) {
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{7, "000111110010010100"},
+		{8, "001000010110111100"},
+		{40, "101000110001101001"},
+	}
+
+	for _, tt := range tests {
+		if got := versionBits(tt.version); got != parseBits(tt.want) {
+			t.Errorf("versionBits(%d) = %018b, want %s", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestValues_AlignmentPositions(t *testing.T) {
+	tests := []struct {
+		version int
+		want    []int
+	}{
+		{1, nil},
+		{2, []int{6, 18}},
+		{7, []int{6, 22, 38}},
+		{14, []int{6, 26, 46, 66}},
+		{32, []int{6, 34, 60, 86, 112, 138}},
+		{40, []int{6, 30, 58, 86, 114, 142, 170}},
+	}
+
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.want, alignmentPositions(tt.version)); diff != "" {
+			t.Errorf("alignmentPositions(%d) mismatch (-want +got):\n%s", tt.version, diff)
+		}
+	}
+}
+
+func TestValues_DataCodewords(t *testing.T) {
+	tests := []struct {
+		version int
+		level   Level
+		want    int
+	}{
+		{1, Low, 19},
+		{1, Medium, 16},
+		{1, High, 9},
+		{5, Quartile, 62},
+		{10, Medium, 216},
+		{27, High, 628},
+		{40, Low, 2956},
+		{40, High, 1276},
+	}
+
+	for _, tt := range tests {
+		if got := dataCodewords(tt.version, tt.level); got != tt.want {
+			t.Errorf("dataCodewords(%d, %v) = %d, want %d", tt.version, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestValues_ReedSolomon(t *testing.T) {
+	// version 1-M examples: "01234567" in numeric mode from ISO/IEC 18004 annex I, and
+	// "HELLO WORLD" in alphanumeric mode
+	tests := []struct {
+		data []byte
+		want []byte
+	}{
+		{
+			[]byte{16, 32, 12, 86, 97, 128, 236, 17, 236, 17, 236, 17, 236, 17, 236, 17},
+			[]byte{165, 36, 212, 193, 237, 54, 199, 135, 44, 85},
+		},
+		{
+			[]byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17},
+			[]byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23},
+		},
+	}
+
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.want, reedSolomonRemainder(tt.data, reedSolomonDivisor(len(tt.want)))); diff != "" {
+			t.Errorf("reedSolomonRemainder(%v) mismatch (-want +got):\n%s", tt.data, diff)
+		}
+	}
+}
+
+func TestValues_Codewords(t *testing.T) {
+	c := newCode(1, Medium)
+
+	// byte mode 0100, count 00001011, the bytes of HELLO WORLD, terminator and pad codewords
+	data := c.dataCodewords([]byte("HELLO WORLD"))
+	if diff := cmp.Diff([]byte{64, 180, 132, 84, 196, 196, 242, 5, 116, 245, 36, 196, 64, 236, 17, 236}, data); diff != "" {
+		t.Errorf("dataCodewords mismatch (-want +got):\n%s", diff)
+	}
+
+	// the first codeword goes up the two rightmost columns from the bottom right corner,
+	// most significant bit first, right column before left
+	codewords := make([]byte, 26)
+	codewords[0] = 0xa5
+
+	c.drawCodewords(codewords)
+
+	var got []bool
+	for y := 20; y >= 17; y-- {
+		got = append(got, c.Dark(20, y), c.Dark(19, y))
+	}
+
+	want := []bool{true, false, true, false, false, true, false, true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("first codeword modules mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValues_Encode(t *testing.T) {
+	tests := []struct {
+		data    string
+		level   Level
+		version int
+		err     error
+	}{
+		{"HELLO WORLD", Medium, 1, nil},
+		{"00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D", Medium, 8, nil},
+		{strings.Repeat("a", 213), Medium, 10, nil},
+		{strings.Repeat("a", 214), Medium, 11, nil},
+		{strings.Repeat("z", 1000), Quartile, 31, nil},
+		{strings.Repeat("x", 2953), Low, 40, nil},
+		{strings.Repeat("x", 2954), Low, 0, ErrTooLong},
+		{"a", Level(4), 0, ErrInvalidLevel},
+	}
+
+	for _, tt := range tests {
+		code, err := Encode([]byte(tt.data), tt.level)
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Encode(%.20q, %v) returned error %v, want %v", tt.data, tt.level, err, tt.err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if code.Version != tt.version {
+			t.Errorf("Encode(%.20q, %v) has version %d, want %d", tt.data, tt.level, code.Version, tt.version)
+		}
+
+		got, err := decode(code)
+		if err != nil {
+			t.Errorf("decoding Encode(%.20q, %v) failed: %v", tt.data, tt.level, err)
+			continue
+		}
+
+		if got != tt.data {
+			t.Errorf("decoding Encode(%.20q, %v) = %.20q", tt.data, tt.level, got)
+		}
+	}
+}
+
+func TestValues_Render(t *testing.T) {
+	code, _ := Encode([]byte("HELLO WORLD"), Medium)
+
+	var buf bytes.Buffer
+	if err := code.WritePNG(&buf, Options{ModuleSize: 2}); err != nil {
+		t.Fatalf("WritePNG returned %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode returned %v", err)
+	}
+
+	// 21 modules and a quiet zone of 4 on each side, 2 pixels each
+	if bounds := img.Bounds(); bounds.Dx() != 58 || bounds.Dy() != 58 {
+		t.Errorf("WritePNG wrote a %dx%d image, want 58x58", bounds.Dx(), bounds.Dy())
+	}
+
+	for _, p := range []struct {
+		x, y int
+		dark bool
+	}{{7, 7, false}, {8, 8, true}, {21, 21, true}, {10, 10, false}, {12, 12, true}} {
+		r, _, _, _ := img.At(p.x, p.y).RGBA()
+		if (r == 0) != p.dark {
+			t.Errorf("pixel %d,%d dark is %v, want %v", p.x, p.y, r == 0, p.dark)
+		}
+	}
+
+	buf.Reset()
+	if err := code.WriteSVG(&buf, Options{}); err != nil {
+		t.Fatalf("WriteSVG returned %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), `<svg xmlns="http://www.w3.org/2000/svg" width="116" height="116" viewBox="0 0 29 29"`) {
+		t.Errorf("WriteSVG wrote an unexpected header: %.100s", buf.String())
+	}
+}
+
+func parseBits(s string) int {
+	value := 0
+	for _, c := range s {
+		value = value<<1 | int(c-'0')
+	}
+
+	return value
+}
+
+// decode reads a code back the way a reader would: format information, unmasking, codeword
+// order, block de-interleaving and Reed-Solomon syndromes, without the encoder's module map
+func decode(c *Code) (string, error) {
+	size := c.Size
+
+	for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := max(abs(dx-3), abs(dy-3))
+				if c.Dark(corner[0]+dx, corner[1]+dy) != (ring != 2) {
+					return "", errors.New("bad finder pattern")
+				}
+			}
+		}
+	}
+
+	for i := 8; i < size-8; i++ {
+		if c.Dark(i, 6) != (i%2 == 0) || c.Dark(6, i) != (i%2 == 0) {
+			return "", errors.New("bad timing pattern")
+		}
+	}
+
+	// first copy of the format information, bit 14 first
+	var first, second int
+	for _, p := range [][2]int{{0, 8}, {1, 8}, {2, 8}, {3, 8}, {4, 8}, {5, 8}, {7, 8}, {8, 8}, {8, 7}, {8, 5}, {8, 4}, {8, 3}, {8, 2}, {8, 1}, {8, 0}} {
+		first <<= 1
+		if c.Dark(p[0], p[1]) {
+			first |= 1
+		}
+	}
+
+	for i := 0; i < 15; i++ {
+		second <<= 1
+
+		x, y := 8, size-1-i
+		if i >= 7 {
+			x, y = size-15+i, 8
+		}
+
+		if c.Dark(x, y) {
+			second |= 1
+		}
+	}
+
+	if first != second || first != formatBits(c.Level, first>>10&7^5) {
+		return "", errors.New("bad format information")
+	}
+
+	mask := first>>10&7 ^ 5
+
+	reserved := func(x, y int) bool {
+		switch {
+		case x <= 8 && y <= 8, x >= size-8 && y <= 8, x <= 8 && y >= size-8:
+			return true
+		case x == 6 || y == 6:
+			return true
+		case c.Version >= 7 && ((x >= size-11 && y < 6) || (y >= size-11 && x < 6)):
+			return true
+		}
+
+		for _, ax := range alignmentPositions(c.Version) {
+			for _, ay := range alignmentPositions(c.Version) {
+				if abs(x-ax) <= 2 && abs(y-ay) <= 2 && !(ax <= 8 && ay <= 8) && !(ax >= size-9 && ay <= 8) && !(ax <= 8 && ay >= size-9) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	masks := []func(x, y int) bool{
+		func(x, y int) bool { return (x+y)%2 == 0 },
+		func(x, y int) bool { return y%2 == 0 },
+		func(x, y int) bool { return x%3 == 0 },
+		func(x, y int) bool { return (x+y)%3 == 0 },
+		func(x, y int) bool { return (x/3+y/2)%2 == 0 },
+		func(x, y int) bool { return x*y%2+x*y%3 == 0 },
+		func(x, y int) bool { return (x*y%2+x*y%3)%2 == 0 },
+		func(x, y int) bool { return ((x+y)%2+x*y%3)%2 == 0 },
+	}
+
+	var bits []bool
+	upward := true
+
+	for column := size - 1; column > 0; column -= 2 {
+		if column == 6 {
+			column--
+		}
+
+		for k := 0; k < size; k++ {
+			y := k
+			if upward {
+				y = size - 1 - k
+			}
+
+			for _, x := range []int{column, column - 1} {
+				if !reserved(x, y) {
+					bits = append(bits, c.Dark(x, y) != masks[mask](x, y))
+				}
+			}
+		}
+
+		upward = !upward
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i := range codewords {
+		for j := 0; j < 8; j++ {
+			codewords[i] <<= 1
+			if bits[i*8+j] {
+				codewords[i] |= 1
+			}
+		}
+	}
+
+	blocks := eccBlocks[c.Level][c.Version]
+	eccLength := eccCodewordsPerBlock[c.Level][c.Version]
+	dataLength := dataCodewords(c.Version, c.Level)
+	longBlocks := dataLength % blocks
+	shortData := dataLength / blocks
+
+	data := make([][]byte, blocks)
+	ecc := make([][]byte, blocks)
+	k := 0
+
+	for i := 0; i <= shortData; i++ {
+		for j := 0; j < blocks; j++ {
+			if i < shortData || j >= blocks-longBlocks {
+				data[j] = append(data[j], codewords[k])
+				k++
+			}
+		}
+	}
+
+	for i := 0; i < eccLength; i++ {
+		for j := 0; j < blocks; j++ {
+			ecc[j] = append(ecc[j], codewords[k])
+			k++
+		}
+	}
+
+	var stream []byte
+
+	for j := 0; j < blocks; j++ {
+		block := append(append([]byte{}, data[j]...), ecc[j]...)
+
+		// every root of the generator polynomial must be a root of the block
+		root := byte(1)
+		for i := 0; i < eccLength; i++ {
+			value := byte(0)
+			for _, b := range block {
+				value = gfMultiply(value, root) ^ b
+			}
+
+			if value != 0 {
+				return "", errors.New("bad Reed-Solomon codewords")
+			}
+
+			root = gfMultiply(root, 2)
+		}
+
+		stream = append(stream, data[j]...)
+	}
+
+	if stream[0]>>4 != 0x4 {
+		return "", errors.New("not byte mode")
+	}
+
+	var reader bitBuffer
+	for _, b := range stream {
+		reader.append(int(b), 8)
+	}
+
+	read := func(offset, length int) int {
+		value := 0
+		for _, set := range reader[offset : offset+length] {
+			value <<= 1
+			if set {
+				value |= 1
+			}
+		}
+
+		return value
+	}
+
+	count := read(4, charCountBits(c.Version))
+
+	var result []byte
+	for i := 0; i < count; i++ {
+		result = append(result, byte(read(4+charCountBits(c.Version)+8*i, 8)))
+	}
+
+	return string(result), nil
+}
@@ -0,0 +1,87 @@
+package qrcode
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// Defaults of Options. The specification asks for a quiet zone of four modules.
+const (
+	DefaultModuleSize = 4
+	DefaultQuietZone  = 4
+)
+
+// Options configures how a QR code is rendered. Zero values are replaced by the defaults.
+type Options struct {
+	// ModuleSize is the width and height in pixels of a module
+	ModuleSize int
+	// QuietZone is the blank margin around the code, in modules
+	QuietZone int
+}
+
+func (o Options) withDefaults() Options {
+	if o.ModuleSize <= 0 {
+		o.ModuleSize = DefaultModuleSize
+	}
+
+	if o.QuietZone <= 0 {
+		o.QuietZone = DefaultQuietZone
+	}
+
+	return o
+}
+
+// Image renders the code as black modules on a white background
+func (c *Code) Image(options Options) image.Image {
+	options = options.withDefaults()
+
+	side := (c.Size + 2*options.QuietZone) * options.ModuleSize
+	img := image.NewGray(image.Rect(0, 0, side, side))
+
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			if c.Dark(x/options.ModuleSize-options.QuietZone, y/options.ModuleSize-options.QuietZone) {
+				img.SetGray(x, y, color.Gray{})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 0xff})
+			}
+		}
+	}
+
+	return img
+}
+
+// WritePNG renders the code as a PNG image
+func (c *Code) WritePNG(w io.Writer, options Options) error {
+	return png.Encode(w, c.Image(options))
+}
+
+// WriteSVG renders the code as an SVG document with a single path of dark modules
+func (c *Code) WriteSVG(w io.Writer, options Options) error {
+	options = options.withDefaults()
+
+	side := c.Size + 2*options.QuietZone
+	pixels := side * options.ModuleSize
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		pixels, pixels, side, side)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#fff"/><path d="`, side, side)
+
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(bw, "M%d %dh1v1h-1z", x+options.QuietZone, y+options.QuietZone)
+			}
+		}
+	}
+
+	bw.WriteString(`"/></svg>` + "\n")
+
+	return bw.Flush()
+}
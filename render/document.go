@@ -0,0 +1,257 @@
+package render
+
+import (
+	"errors"
+	"github.com/fonini/go-boleto-utils/banks"
+	"github.com/fonini/go-boleto-utils/generator"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPaymentPlace is printed as the local de pagamento when a Document leaves it empty
+const DefaultPaymentPlace = "Pagável em qualquer banco até o vencimento"
+
+const dateFormat = "02/01/2006"
+
+var ErrInvalidDocument = errors.New("invalid boleto document")
+
+// Party is the beneficiário (who receives the payment) or the pagador (who pays) of a boleto
+type Party struct {
+	Name     string
+	Document string // CPF or CNPJ, as it should be printed
+	Address  string
+}
+
+// Document holds what is printed on a boleto. Barcode, DigitableLine and Boleto are filled by
+// New or FromCode; the remaining fields come from the issuer. Empty BeneficiaryCode, OurNumber
+// and Wallet are taken from the decoded free field of the boleto.
+type Document struct {
+	Barcode       string
+	DigitableLine string
+	Boleto        *utils.Boleto
+
+	// Logo is drawn in the bank logo slot; the bank name is printed when it is nil
+	Logo image.Image
+
+	PaymentPlace    string
+	Beneficiary     Party
+	BeneficiaryCode string
+	Payer           Party
+	DocumentNumber  string
+	DocumentKind    string
+	Acceptance      string
+	DocumentDate    time.Time
+	ProcessingDate  time.Time
+	OurNumber       string
+	Wallet          string
+	Instructions    []string
+}
+
+// New generates the codes of request and returns a Document for them
+func New(request generator.Request) (*Document, error) {
+	result, err := generator.Generate(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// the due date of the request resolves the due date factor rollover
+	boleto, err := parser.ParseWithOptions(result.Barcode, parser.Options{ReferenceDate: request.DueDate})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Barcode:       result.Barcode,
+		DigitableLine: result.DigitableLine,
+		Boleto:        boleto,
+	}, nil
+}
+
+// FromCode parses a bank barcode or digitable line and returns a Document for it
+func FromCode(code string) (*Document, error) {
+	boleto, err := parser.Parse(code)
+	if err != nil {
+		return nil, err
+	}
+
+	digits := utils.OnlyNumbers(code)
+
+	if boleto.CodeType == parser.DigitableLine {
+		digits, err = parser.ConvertDigitableLineToBarcode(digits)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Document{
+		Barcode:       digits,
+		DigitableLine: parser.FormatDigitableLine(parser.ConvertBarcodeToDigitableLine(digits)),
+		Boleto:        boleto,
+	}, nil
+}
+
+// Fields are the printed texts of a Document, shared by the PDF and HTML layouts
+type Fields struct {
+	BankName        string
+	BankCode        string
+	DigitableLine   string
+	PaymentPlace    string
+	DueDate         string
+	Beneficiary     string
+	BeneficiaryCode string
+	Payer           string
+	PayerAddress    string
+	DocumentDate    string
+	DocumentNumber  string
+	DocumentKind    string
+	Acceptance      string
+	ProcessingDate  string
+	OurNumber       string
+	Wallet          string
+	Currency        string
+	Amount          string
+	Instructions    []string
+}
+
+// Fields formats the texts printed on the boleto of d
+func (d *Document) Fields() (*Fields, error) {
+	if d == nil || d.Boleto == nil || len(d.Barcode) != 44 {
+		return nil, ErrInvalidDocument
+	}
+
+	boleto := d.Boleto
+
+	v := &Fields{
+		BankName:        boleto.IssuerBankName,
+		BankCode:        bankCodeWithDigit(boleto.IssuerBankCode),
+		DigitableLine:   d.DigitableLine,
+		PaymentPlace:    d.PaymentPlace,
+		DueDate:         boleto.DueDate.Format(dateFormat),
+		Beneficiary:     partyLine(d.Beneficiary.Name, d.Beneficiary.Document),
+		BeneficiaryCode: d.BeneficiaryCode,
+		Payer:           partyLine(d.Payer.Name, d.Payer.Document),
+		PayerAddress:    d.Payer.Address,
+		DocumentDate:    formatDate(d.DocumentDate),
+		DocumentNumber:  d.DocumentNumber,
+		DocumentKind:    d.DocumentKind,
+		Acceptance:      d.Acceptance,
+		ProcessingDate:  formatDate(d.ProcessingDate),
+		OurNumber:       d.OurNumber,
+		Wallet:          d.Wallet,
+		Currency:        "R$",
+		Amount:          strings.TrimPrefix(boleto.Amount.String(), "R$ "),
+		Instructions:    d.Instructions,
+	}
+
+	if v.BankName == "" {
+		if bank, err := banks.ByCode(boleto.IssuerBankCode); err == nil {
+			v.BankName = bank.Name
+		}
+	}
+
+	if v.DigitableLine == "" {
+		v.DigitableLine = parser.FormatDigitableLine(parser.ConvertBarcodeToDigitableLine(d.Barcode))
+	}
+
+	if v.PaymentPlace == "" {
+		v.PaymentPlace = DefaultPaymentPlace
+	}
+
+	// a zero due date factor means the boleto has no due date
+	if d.Barcode[5:9] == "0000" {
+		v.DueDate = "Contra apresentação"
+	}
+
+	if boleto.Currency != generator.RealCurrency {
+		v.Currency = strconv.Itoa(boleto.Currency)
+	}
+
+	if d.Beneficiary.Address != "" {
+		v.Beneficiary += " - " + d.Beneficiary.Address
+	}
+
+	if f := boleto.FreeField; f != nil {
+		if v.BeneficiaryCode == "" {
+			code := joinDigit(f.Account, f.AccountDigit)
+			if code == "" {
+				code = f.Agreement
+			}
+
+			v.BeneficiaryCode = f.Agency
+			if f.Agency != "" && code != "" {
+				v.BeneficiaryCode += " / "
+			}
+			v.BeneficiaryCode += code
+		}
+
+		if v.OurNumber == "" {
+			v.OurNumber = joinDigit(f.OurNumber, f.OurNumberDigit)
+		}
+
+		if v.Wallet == "" {
+			v.Wallet = f.Wallet
+		}
+	}
+
+	return v, nil
+}
+
+// bankCheckDigits holds the bank code check digits printed differently from the module 11 rule
+var bankCheckDigits = map[string]string{
+	"104": "0",
+}
+
+// bankCodeWithDigit returns the bank code followed by its module 11 check digit, e.g. 341-7
+func bankCodeWithDigit(code string) string {
+	if digit, ok := bankCheckDigits[code]; ok {
+		return code + "-" + digit
+	}
+
+	sum, weight := 0, 2
+
+	for i := len(code) - 1; i >= 0; i-- {
+		sum += int(code[i]-'0') * weight
+		weight++
+	}
+
+	switch digit := 11 - sum%11; digit {
+	case 11:
+		return code + "-0"
+	case 10:
+		return code + "-X"
+	default:
+		return code + "-" + strconv.Itoa(digit)
+	}
+}
+
+func partyLine(name, document string) string {
+	if document == "" {
+		return name
+	}
+
+	if name == "" {
+		return document
+	}
+
+	return name + " - " + document
+}
+
+func joinDigit(number, digit string) string {
+	if number == "" || digit == "" {
+		return number
+	}
+
+	return number + "-" + digit
+}
+
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+
+	return date.Format(dateFormat)
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"github.com/fonini/go-boleto-utils/barcode"
+	"html/template"
+	"image/png"
+	"io"
+	"sync"
+)
+
+//go:embed templates/boleto.html
+var templates embed.FS
+
+// defaultTemplate is executed for the banks with no registered template
+var defaultTemplate = NewTemplate()
+
+// NewTemplate returns a new copy of the default template, which renders the ficha de
+// compensação and the recibo do pagador as an HTML page. It is executed as "boleto" and is
+// built from the "style", "header", "receipt" and "slip" templates, so a bank's visual
+// differences can be accommodated by parsing new definitions of only the parts that change.
+func NewTemplate() *template.Template {
+	return template.Must(template.ParseFS(templates, "templates/boleto.html"))
+}
+
+var (
+	mu            sync.RWMutex
+	bankTemplates = map[string]*template.Template{}
+)
+
+// TemplateData is passed to the HTML templates: the printed fields of the boleto, its
+// barcode as an inline SVG document and the logo, when the Document has one, as a data URL
+type TemplateData struct {
+	Fields
+	BarcodeSVG template.HTML
+	LogoURL    template.URL
+}
+
+// RegisterTemplate sets the template WriteHTML uses for boletos issued by bankCode, replacing
+// any previous one. The template must define "boleto".
+func RegisterTemplate(bankCode string, t *template.Template) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	bankTemplates[bankCode] = t
+}
+
+// LookupTemplate returns the template registered for bankCode
+func LookupTemplate(bankCode string) (*template.Template, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	t, ok := bankTemplates[bankCode]
+
+	return t, ok
+}
+
+// WriteHTML writes the boleto as an HTML page, with the template registered for its bank or
+// the default one
+func WriteHTML(w io.Writer, document *Document) error {
+	if document == nil || document.Boleto == nil {
+		return ErrInvalidDocument
+	}
+
+	t, ok := LookupTemplate(document.Boleto.IssuerBankCode)
+	if !ok {
+		t = defaultTemplate
+	}
+
+	return WriteHTMLTemplate(w, t, document)
+}
+
+// WriteHTMLTemplate writes the boleto by executing the "boleto" template of t with its TemplateData
+func WriteHTMLTemplate(w io.Writer, t *template.Template, document *Document) error {
+	data, err := document.templateData()
+	if err != nil {
+		return err
+	}
+
+	return t.ExecuteTemplate(w, "boleto", data)
+}
+
+func (d *Document) templateData() (*TemplateData, error) {
+	fields, err := d.Fields()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &TemplateData{Fields: *fields}
+
+	var svg bytes.Buffer
+	if err := barcode.WriteSVG(&svg, d.Barcode, barcode.Options{}); err != nil {
+		return nil, err
+	}
+
+	// the SVG is generated from digits only, so it is safe to embed as is
+	data.BarcodeSVG = template.HTML(svg.String())
+
+	if d.Logo != nil {
+		var logo bytes.Buffer
+		if err := png.Encode(&logo, d.Logo); err != nil {
+			return nil, err
+		}
+
+		data.LogoURL = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+
+	return data, nil
+}
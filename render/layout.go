@@ -0,0 +1,236 @@
+package render
+
+import (
+	"github.com/fonini/go-boleto-utils/barcode"
+	"io"
+)
+
+// Page and layout dimensions, in points
+const (
+	mm = 72 / 25.4
+
+	pageWidth  = 210 * mm
+	pageHeight = 297 * mm
+
+	left        = 15 * mm
+	width       = 180 * mm
+	right       = left + width
+	columnWidth = 45 * mm
+	column      = right - columnWidth
+	rowHeight   = 9 * mm
+
+	labelSize = 5.5
+	valueSize = 8.5
+
+	// FEBRABAN prints the barcode 103 mm wide and 13 mm high
+	barcodeWidth  = 103 * mm
+	barcodeHeight = 13 * mm
+)
+
+// WritePDF writes the boleto as an A4 PDF page with the recibo do pagador on top and the
+// ficha de compensação, with the ITF barcode, below the cut line
+func WritePDF(w io.Writer, document *Document) error {
+	v, err := document.Fields()
+	if err != nil {
+		return err
+	}
+
+	pattern, err := barcode.Encode(document.Barcode)
+	if err != nil {
+		return err
+	}
+
+	c := &canvas{height: pageHeight}
+
+	var images []pdfImage
+	if document.Logo != nil {
+		images = append(images, pdfImage{name: "Logo", img: document.Logo})
+	}
+
+	drawReceipt(c, v, document, 15*mm)
+
+	c.dashedLine(left, 78*mm, right, 78*mm, 0.5)
+	c.textRight(right, 76.5*mm, regular, labelSize, "Corte na linha pontilhada")
+
+	drawSlip(c, v, document, 85*mm, pattern)
+
+	return writePDF(w, pageWidth, pageHeight, c, images)
+}
+
+// drawReceipt draws the recibo do pagador, kept by the payer
+func drawReceipt(c *canvas, v *Fields, document *Document, y float64) {
+	drawHeader(c, v, document, y)
+	y += 10 * mm
+
+	field(c, left, y, width-columnWidth, "Beneficiário", v.Beneficiary, false)
+	field(c, column, y, columnWidth, "Vencimento", v.DueDate, true)
+	y += rowHeight
+
+	field(c, left, y, width-columnWidth, "Pagador", v.Payer, false)
+	field(c, column, y, columnWidth, "(=) Valor do documento", v.Amount, true)
+	y += rowHeight
+
+	field(c, left, y, columnWidth, "Agência / Código do beneficiário", v.BeneficiaryCode, false)
+	field(c, left+columnWidth, y, columnWidth, "Nº do documento", v.DocumentNumber, false)
+	field(c, left+2*columnWidth, y, columnWidth, "Nosso número", v.OurNumber, false)
+	field(c, column, y, columnWidth, "(=) Valor cobrado", "", true)
+	y += rowHeight
+
+	c.text(left, y+3*mm, bold, 7, "Recibo do Pagador")
+	c.textRight(right, y+3*mm, regular, labelSize, "Autenticação mecânica")
+}
+
+// drawSlip draws the ficha de compensação, kept by the bank that receives the payment
+func drawSlip(c *canvas, v *Fields, document *Document, y float64, pattern string) {
+	drawHeader(c, v, document, y)
+	y += 10 * mm
+
+	field(c, left, y, width-columnWidth, "Local de pagamento", v.PaymentPlace, false)
+	field(c, column, y, columnWidth, "Vencimento", v.DueDate, true)
+	y += rowHeight
+
+	field(c, left, y, width-columnWidth, "Beneficiário", v.Beneficiary, false)
+	field(c, column, y, columnWidth, "Agência / Código do beneficiário", v.BeneficiaryCode, true)
+	y += rowHeight
+
+	x := left
+	for _, f := range []struct {
+		width float64
+		label string
+		value string
+	}{
+		{25 * mm, "Data do documento", v.DocumentDate},
+		{35 * mm, "Nº do documento", v.DocumentNumber},
+		{20 * mm, "Espécie doc.", v.DocumentKind},
+		{15 * mm, "Aceite", v.Acceptance},
+		{40 * mm, "Data processamento", v.ProcessingDate},
+	} {
+		field(c, x, y, f.width, f.label, f.value, false)
+		x += f.width
+	}
+	field(c, column, y, columnWidth, "Nosso número", v.OurNumber, true)
+	y += rowHeight
+
+	x = left
+	for _, f := range []struct {
+		width float64
+		label string
+		value string
+	}{
+		{25 * mm, "Uso do banco", ""},
+		{20 * mm, "Carteira", v.Wallet},
+		{15 * mm, "Espécie", v.Currency},
+		{35 * mm, "Quantidade", ""},
+		{40 * mm, "Valor", ""},
+	} {
+		field(c, x, y, f.width, f.label, f.value, false)
+		x += f.width
+	}
+	field(c, column, y, columnWidth, "(=) Valor do documento", v.Amount, true)
+	y += rowHeight
+
+	instructionsHeight := 5 * rowHeight
+
+	c.strokeRect(left, y, width-columnWidth, instructionsHeight)
+	c.text(left+1*mm, y+2.3*mm, regular, labelSize, "Instruções (texto de responsabilidade do beneficiário)")
+
+	for i, instruction := range v.Instructions {
+		if i == 10 {
+			break
+		}
+
+		c.text(left+1*mm, y+6.5*mm+float64(i)*4*mm, regular, valueSize, fit(instruction, valueSize, width-columnWidth-2*mm))
+	}
+
+	for i, label := range []string{
+		"(-) Desconto / Abatimento",
+		"(-) Outras deduções",
+		"(+) Mora / Multa",
+		"(+) Outros acréscimos",
+		"(=) Valor cobrado",
+	} {
+		field(c, column, y+float64(i)*rowHeight, columnWidth, label, "", true)
+	}
+	y += instructionsHeight
+
+	c.strokeRect(left, y, width, 16*mm)
+	c.text(left+1*mm, y+2.3*mm, regular, labelSize, "Pagador")
+	c.text(left+1*mm, y+6.5*mm, regular, valueSize, fit(v.Payer, valueSize, width-2*mm))
+	c.text(left+1*mm, y+10.5*mm, regular, valueSize, fit(v.PayerAddress, valueSize, width-2*mm))
+	y += 16 * mm
+
+	c.textRight(right, y+2.5*mm, regular, labelSize, "Autenticação mecânica - Ficha de Compensação")
+
+	drawBarcode(c, left, y+4*mm, pattern)
+}
+
+// drawHeader draws the bank logo or name, the bank code and the digitable line
+func drawHeader(c *canvas, v *Fields, document *Document, y float64) {
+	if document.Logo != nil {
+		bounds := document.Logo.Bounds()
+
+		// fit the logo in a 38 x 8 mm slot, keeping its aspect ratio
+		w, h := 38*mm, 8*mm
+		if ratio := float64(bounds.Dx()) / float64(bounds.Dy()); ratio < w/h {
+			w = h * ratio
+		} else {
+			h = w / ratio
+		}
+
+		c.image("Logo", left, y+9*mm-h, w, h)
+	} else {
+		c.text(left, y+8*mm, bold, 9, fit(v.BankName, 9, 38*mm))
+	}
+
+	c.line(left+40*mm, y+2*mm, left+40*mm, y+10*mm, 1)
+	c.text(left+42*mm, y+8.5*mm, bold, 14, v.BankCode)
+	c.line(left+62*mm, y+2*mm, left+62*mm, y+10*mm, 1)
+	c.textRight(right, y+8.5*mm, bold, 11, v.DigitableLine)
+	c.line(left, y+10*mm, right, y+10*mm, 1)
+}
+
+// field draws a labelled box with its value left or right aligned
+func field(c *canvas, x, y, w float64, label, value string, alignRight bool) {
+	c.strokeRect(x, y, w, rowHeight)
+	c.text(x+1*mm, y+2.3*mm, regular, labelSize, fit(label, labelSize, w-2*mm))
+
+	if value == "" {
+		return
+	}
+
+	value = fit(value, valueSize, w-2*mm)
+
+	if alignRight {
+		c.textRight(x+w-1*mm, y+rowHeight-2*mm, regular, valueSize, value)
+	} else {
+		c.text(x+1*mm, y+rowHeight-2*mm, regular, valueSize, value)
+	}
+}
+
+// drawBarcode draws the bars of an ITF pattern with wide bars three times the narrow ones,
+// scaled to the FEBRABAN width
+func drawBarcode(c *canvas, x, y float64, pattern string) {
+	units := 0
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == barcode.Wide {
+			units += 3
+		} else {
+			units++
+		}
+	}
+
+	narrow := barcodeWidth / float64(units)
+
+	for i := 0; i < len(pattern); i++ {
+		w := narrow
+		if pattern[i] == barcode.Wide {
+			w = 3 * narrow
+		}
+
+		if i%2 == 0 {
+			c.fillRect(x, y, w, barcodeHeight)
+		}
+
+		x += w
+	}
+}
@@ -0,0 +1,268 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Fonts of a page. Both are standard Type 1 fonts every PDF reader ships, so nothing is embedded.
+type font string
+
+const (
+	regular font = "F1"
+	bold    font = "F2"
+)
+
+// helveticaWidths holds the Helvetica advance widths, in thousandths of the font size, of the
+// printable ASCII characters starting at the space. Helvetica-Bold has the same widths for
+// digits, spaces and the punctuation of amounts, dates and digitable lines, the only text
+// that is measured.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// winAnsiExtra maps the characters WinAnsiEncoding places outside the Latin-1 range
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80,
+	'‘': 0x91,
+	'’': 0x92,
+	'“': 0x93,
+	'”': 0x94,
+	'•': 0x95,
+	'–': 0x96,
+	'—': 0x97,
+}
+
+// winAnsi encodes s for the WinAnsiEncoding of the standard fonts. Characters it cannot
+// represent are replaced by a question mark.
+func winAnsi(s string) []byte {
+	encoded := make([]byte, 0, len(s))
+
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xa0 && r <= 0xff):
+			encoded = append(encoded, byte(r))
+		case winAnsiExtra[r] != 0:
+			encoded = append(encoded, winAnsiExtra[r])
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+
+	return encoded
+}
+
+// textWidth returns the width in points of s set in Helvetica. Characters outside ASCII,
+// mostly accented letters, are measured as a lowercase letter.
+func textWidth(s string, size float64) float64 {
+	width := 0
+
+	for _, r := range s {
+		if r >= ' ' && r <= '~' {
+			width += helveticaWidths[r-' ']
+		} else {
+			width += 556
+		}
+	}
+
+	return float64(width) * size / 1000
+}
+
+// fit shortens s with an ellipsis so it is at most width points wide
+func fit(s string, size float64, width float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+// canvas collects the drawing operators of a page. Coordinates are in points with the
+// origin at the top left corner, and are flipped to the bottom-left origin of PDF on output.
+type canvas struct {
+	buf    bytes.Buffer
+	height float64
+}
+
+func number(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+
+	if s == "-0" || s == "" {
+		return "0"
+	}
+
+	return s
+}
+
+func (c *canvas) fillRect(x, y, w, h float64) {
+	fmt.Fprintf(&c.buf, "%s %s %s %s re f\n", number(x), number(c.height-y-h), number(w), number(h))
+}
+
+func (c *canvas) strokeRect(x, y, w, h float64) {
+	fmt.Fprintf(&c.buf, "0.5 w %s %s %s %s re S\n", number(x), number(c.height-y-h), number(w), number(h))
+}
+
+func (c *canvas) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&c.buf, "%s w %s %s m %s %s l S\n", number(width), number(x1), number(c.height-y1), number(x2), number(c.height-y2))
+}
+
+func (c *canvas) dashedLine(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&c.buf, "q [3 2] 0 d ")
+	c.line(x1, y1, x2, y2, width)
+	fmt.Fprintf(&c.buf, "Q\n")
+}
+
+// text draws s with its baseline at y
+func (c *canvas) text(x, y float64, f font, size float64, s string) {
+	fmt.Fprintf(&c.buf, "BT /%s %s Tf %s %s Td (", f, number(size), number(x), number(c.height-y))
+
+	for _, b := range winAnsi(s) {
+		if b == '(' || b == ')' || b == '\\' {
+			c.buf.WriteByte('\\')
+		}
+		c.buf.WriteByte(b)
+	}
+
+	c.buf.WriteString(") Tj ET\n")
+}
+
+// textRight draws s ending at x
+func (c *canvas) textRight(x, y float64, f font, size float64, s string) {
+	c.text(x-textWidth(s, size), y, f, size, s)
+}
+
+// image draws the image XObject name scaled to the given box
+func (c *canvas) image(name string, x, y, w, h float64) {
+	fmt.Fprintf(&c.buf, "q %s 0 0 %s %s %s cm /%s Do Q\n", number(w), number(h), number(x), number(c.height-y-h), name)
+}
+
+// pdfImage is an image XObject of a page
+type pdfImage struct {
+	name string
+	img  image.Image
+}
+
+// writePDF writes a single-page PDF document with the drawing of c and the images it uses
+func writePDF(w io.Writer, width, height float64, c *canvas, images []pdfImage) error {
+	content, err := deflate(c.buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	var xobjects strings.Builder
+	for i, img := range images {
+		fmt.Fprintf(&xobjects, "/%s %d 0 R ", img.name, 7+i)
+	}
+
+	objects := [][]byte{
+		[]byte("<< /Type /Catalog /Pages 2 0 R >>"),
+		[]byte("<< /Type /Pages /Kids [3 0 R] /Count 1 >>"),
+		[]byte(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /%s 4 0 R /%s 5 0 R >> /XObject << %s>> >> /Contents 6 0 R >>",
+			number(width), number(height), regular, bold, xobjects.String())),
+		[]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"),
+		[]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>"),
+		stream(fmt.Sprintf("/Length %d /Filter /FlateDecode", len(content)), content),
+	}
+
+	for _, img := range images {
+		object, err := imageObject(img.img)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, object)
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, len(objects))
+
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(object)
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
+
+func stream(dictionary string, data []byte) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "<< %s >>\nstream\n", dictionary)
+	buf.Write(data)
+	buf.WriteString("\nendstream")
+
+	return buf.Bytes()
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := zlib.NewWriter(&buf)
+
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// imageObject encodes img as an RGB image XObject. Transparent pixels are blended over white.
+func imageObject(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	pixels := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+
+			pixels = append(pixels, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+
+	data, err := deflate(pixels)
+	if err != nil {
+		return nil, err
+	}
+
+	return stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Length %d /Filter /FlateDecode",
+		bounds.Dx(), bounds.Dy(), len(data)), data), nil
+}
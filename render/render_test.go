@@ -0,0 +1,304 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"github.com/fonini/go-boleto-utils/generator"
+	"html/template"
+	"image"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testDocument(t *testing.T) *Document {
+	document, err := New(generator.Request{
+		BankCode:  "341",
+		Currency:  generator.RealCurrency,
+		DueDate:   time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC),
+		Amount:    116037,
+		FreeField: "1092664672997197273480000",
+	})
+	if err != nil {
+		t.Fatalf("New returned %v", err)
+	}
+
+	document.Beneficiary = Party{Name: "Padaria Pão Quente Ltda", Document: "12.345.678/0001-90"}
+	document.Payer = Party{Name: "João da Silva", Document: "123.456.789-09", Address: "Rua das Flores, 10 - São Paulo/SP"}
+	document.DocumentNumber = "NF 1234"
+	document.DocumentDate = time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	document.Instructions = []string{"Não receber após o vencimento (multa de 2%)"}
+
+	return document
+}
+
+func TestValues_Fields(t *testing.T) {
+	tests := []struct {
+		code string
+		want Fields
+	}{
+		{"34191990600000005001092664672997197273480000",
+			Fields{
+				BankName:        "Itaú Unibanco S.A.",
+				BankCode:        "341-7",
+				DigitableLine:   "34191.09263 64672.997190 72734.800005 1 99060000000500",
+				PaymentPlace:    DefaultPaymentPlace,
+				DueDate:         "20/11/2024",
+				BeneficiaryCode: "7197 / 27348-0",
+				OurNumber:       "26646729-9",
+				Wallet:          "109",
+				Currency:        "R$",
+				Amount:          "5,00",
+			},
+		},
+		{"74891.12156 77703.702280 00002.821056 6 00000000084536",
+			Fields{
+				BankName:        "Banco Cooperativo Sicredi S.A.",
+				BankCode:        "748-X",
+				DigitableLine:   "74891.12156 77703.702280 00002.821056 6 00000000084536",
+				PaymentPlace:    DefaultPaymentPlace,
+				DueDate:         "Contra apresentação",
+				BeneficiaryCode: "0228 / 00282",
+				OurNumber:       "21577703-7",
+				Wallet:          "1",
+				Currency:        "R$",
+				Amount:          "845,36",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		document, err := FromCode(tt.code)
+		if err != nil {
+			t.Errorf("FromCode(%v) returned %v", tt.code, err)
+			continue
+		}
+
+		got, err := document.Fields()
+		if err != nil {
+			t.Errorf("Fields(%v) returned %v", tt.code, err)
+			continue
+		}
+
+		if diff := cmp.Diff(tt.want, *got); diff != "" {
+			t.Errorf("Fields(%v) mismatch (-want +got):\n%s", tt.code, diff)
+		}
+	}
+}
+
+func TestValues_BankCodeWithDigit(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"001", "001-9"},
+		{"033", "033-7"},
+		{"104", "104-0"},
+		{"237", "237-2"},
+		{"341", "341-7"},
+		{"748", "748-X"},
+		{"756", "756-0"},
+	}
+
+	for _, tt := range tests {
+		if got := bankCodeWithDigit(tt.input); got != tt.want {
+			t.Errorf("bankCodeWithDigit(%v) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+// pdfContent checks the cross-reference table of a PDF and returns its decompressed page content
+func pdfContent(t *testing.T, pdf []byte) string {
+	t.Helper()
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("PDF has no header or trailer")
+	}
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if startxref == nil {
+		t.Fatalf("PDF has no startxref")
+	}
+
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point to the xref table", xref)
+	}
+
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1) {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if !bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))) {
+			t.Errorf("xref entry of object %d points to offset %d", i+1, offset)
+		}
+	}
+
+	start := bytes.Index(pdf, []byte("6 0 obj\n"))
+	start += bytes.Index(pdf[start:], []byte("stream\n")) + len("stream\n")
+	end := start + bytes.Index(pdf[start:], []byte("\nendstream"))
+
+	zr, err := zlib.NewReader(bytes.NewReader(pdf[start:end]))
+	if err != nil {
+		t.Fatalf("content stream is not deflated: %v", err)
+	}
+
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("content stream is not deflated: %v", err)
+	}
+
+	return string(content)
+}
+
+func TestValues_WritePDF(t *testing.T) {
+	document := testDocument(t)
+
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, document); err != nil {
+		t.Fatalf("WritePDF returned %v", err)
+	}
+
+	content := pdfContent(t, buf.Bytes())
+
+	for _, want := range []string{
+		"(" + document.DigitableLine + ") Tj",
+		"(341-7) Tj",
+		"(Ita\xfa Unibanco S.A.) Tj",
+		"(20/11/2024) Tj",
+		"(1.160,37) Tj",
+		"(Padaria P\xe3o Quente Ltda - 12.345.678/0001-90) Tj",
+		"(Rua das Flores, 10 - S\xe3o Paulo/SP) Tj",
+		"(N\xe3o receber ap\xf3s o vencimento \\(multa de 2%\\)) Tj",
+		"(Autentica\xe7\xe3o mec\xe2nica - Ficha de Compensa\xe7\xe3o) Tj",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("PDF content does not contain %q", want)
+		}
+	}
+
+	// 2 start bars, 5 bars per digit pair and 2 stop bars
+	if got := strings.Count(content, " re f\n"); got != 2+22*5+2 {
+		t.Errorf("PDF has %d bars, want %d", got, 2+22*5+2)
+	}
+
+	if bytes.Contains(buf.Bytes(), []byte("/Subtype /Image")) {
+		t.Errorf("PDF without a logo has an image")
+	}
+}
+
+func TestValues_WritePDFLogo(t *testing.T) {
+	document := testDocument(t)
+	document.Logo = image.NewRGBA(image.Rect(0, 0, 120, 30))
+
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, document); err != nil {
+		t.Fatalf("WritePDF returned %v", err)
+	}
+
+	content := pdfContent(t, buf.Bytes())
+
+	if !bytes.Contains(buf.Bytes(), []byte("/Subtype /Image /Width 120 /Height 30")) || !strings.Contains(content, "/Logo Do") {
+		t.Errorf("PDF does not draw the logo")
+	}
+
+	if strings.Contains(content, "(Ita\xfa Unibanco S.A.) Tj") {
+		t.Errorf("PDF with a logo prints the bank name")
+	}
+}
+
+func TestValues_WritePDFErrors(t *testing.T) {
+	tests := []struct {
+		input *Document
+		err   error
+	}{
+		{nil, ErrInvalidDocument},
+		{&Document{Barcode: "34191990600000005001092664672997197273480000"}, ErrInvalidDocument},
+	}
+
+	for _, tt := range tests {
+		if err := WritePDF(io.Discard, tt.input); !errors.Is(err, tt.err) {
+			t.Errorf("WritePDF(%+v) returned %v, want %v", tt.input, err, tt.err)
+		}
+	}
+}
+
+func TestValues_WriteHTML(t *testing.T) {
+	document := testDocument(t)
+	document.Payer.Name = "João <script>alert(1)</script>"
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, document); err != nil {
+		t.Fatalf("WriteHTML returned %v", err)
+	}
+
+	page := buf.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<div class="digitable-line">` + document.DigitableLine + "</div>",
+		`<div class="bank-code">341-7</div>`,
+		`<div class="logo">Itaú Unibanco S.A.</div>`,
+		"João &lt;script&gt;alert(1)&lt;/script&gt; - 123.456.789-09",
+		"Padaria Pão Quente Ltda - 12.345.678/0001-90",
+		"Não receber após o vencimento (multa de 2%)<br>",
+		`<div class="barcode"><svg xmlns="http://www.w3.org/2000/svg"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML does not contain %q", want)
+		}
+	}
+
+	if strings.Contains(page, "<script>") {
+		t.Errorf("HTML does not escape the document fields")
+	}
+}
+
+func TestValues_WriteHTMLLogo(t *testing.T) {
+	document := testDocument(t)
+	document.Logo = image.NewRGBA(image.Rect(0, 0, 120, 30))
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, document); err != nil {
+		t.Fatalf("WriteHTML returned %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `<img src="data:image/png;base64,`) {
+		t.Errorf("HTML does not embed the logo")
+	}
+}
+
+func TestValues_RegisterTemplate(t *testing.T) {
+	custom := template.Must(NewTemplate().Parse(`{{define "header"}}<header>{{.BankCode}} {{.DigitableLine}}</header>{{end}}`))
+
+	RegisterTemplate("341", custom)
+	defer func() {
+		mu.Lock()
+		delete(bankTemplates, "341")
+		mu.Unlock()
+	}()
+
+	document := testDocument(t)
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, document); err != nil {
+		t.Fatalf("WriteHTML returned %v", err)
+	}
+
+	if got := strings.Count(buf.String(), "<header>341-7 "+document.DigitableLine+"</header>"); got != 2 {
+		t.Errorf("HTML has %d custom headers, want 2", got)
+	}
+
+	if _, ok := LookupTemplate("237"); ok {
+		t.Errorf("LookupTemplate(237) found a template")
+	}
+
+	if err := WriteHTML(io.Discard, nil); !errors.Is(err, ErrInvalidDocument) {
+		t.Errorf("WriteHTML(nil) returned %v, want %v", err, ErrInvalidDocument)
+	}
+}
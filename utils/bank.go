@@ -4,9 +4,17 @@ import "time"
 
 const BaseDate = "1997-10-07 00:00:00"
 
+// FactorResetDate is the day the due date factor went back from 9999 to 1000
+const FactorResetDate = "2025-02-22 00:00:00"
+
+// FactorCycle is the number of days covered by factors 1000 to 9999 before they repeat
+const FactorCycle = 9000
+
 type Boleto struct {
-	IssuerBankCode    string
-	IssuerBankName    string
+	IssuerBankCode string
+	IssuerBankName string
+	// UnknownIssuer is set when the bank registry has no bank with IssuerBankCode
+	UnknownIssuer     bool
 	Currency          int
 	IssuerReserved1   string
 	CheckDigit1       int
@@ -16,10 +24,45 @@ type Boleto struct {
 	CheckDigit3       int
 	GeneralCheckDigit int
 	DueDate           time.Time
-	Amount            float64
+	Amount            Money
+	CodeType          BoletoCodeType
+	FreeField         *FreeField
+}
+
+// FreeField holds the bank-specific fields encoded in the 25-digit free field (campo livre)
+// of a bank boleto. Fields a bank does not encode are left empty, and fields without a
+// common meaning across banks are stored in Extra.
+type FreeField struct {
+	Agency         string
+	Account        string
+	AccountDigit   string
+	Wallet         string
+	Agreement      string
+	OurNumber      string
+	OurNumberDigit string
+	Extra          map[string]string
+}
+
+// Arrecadacao holds the fields of a FEBRABAN arrecadação (convênio) code, used by
+// utility companies, city halls and government agencies instead of the bank layout.
+type Arrecadacao struct {
+	ProductID         string
+	Segment           BoletoType
+	SegmentCode       string
+	ValueIdentifier   int
+	GeneralCheckDigit int
+	Amount            Money
+	CompanyID         string
+	FreeField         string
+	CheckDigit1       int
+	CheckDigit2       int
+	CheckDigit3       int
+	CheckDigit4       int
 	CodeType          BoletoCodeType
 }
 
+// Banks maps the compe codes of banks to their names. The banks package holds a fuller
+// registry, with ISPBs and a loader for the Banco Central participants file.
 var Banks = map[string]string{
 	"001": "Banco do Brasil S.A.",
 	"003": "Banco da Amazônia S.A.",
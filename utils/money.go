@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidMoney is returned by ParseMoney when the amount cannot be read
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// Money is an exact amount in centavos
+type Money int64
+
+// String formats the amount in BRL, e.g. "R$ 1.160,37"
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	reais := strconv.FormatInt(cents/100, 10)
+
+	var grouped strings.Builder
+	for i, digit := range reais {
+		if i > 0 && (len(reais)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return sign + "R$ " + grouped.String() + "," + strconv.FormatInt(cents%100+100, 10)[1:]
+}
+
+// Decimal formats the amount with a dot and two decimal places, e.g. "1160.37"
+func (m Money) Decimal() string {
+	sign := ""
+	cents := int64(m)
+
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	return sign + strconv.FormatInt(cents/100, 10) + "." + strconv.FormatInt(cents%100+100, 10)[1:]
+}
+
+// ParseMoney parses an amount in reais with up to two decimal places. Both "1160.37" and
+// the BRL notation "R$ 1.160,37" are accepted: when a comma is present it is the decimal
+// separator and dots are thousands separators.
+func ParseMoney(amount string) (Money, error) {
+	amount = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(amount), "R$"))
+
+	sign := int64(1)
+	if strings.HasPrefix(amount, "-") {
+		sign = -1
+		amount = amount[1:]
+	}
+
+	if strings.Contains(amount, ",") {
+		amount = strings.ReplaceAll(amount, ".", "")
+		amount = strings.Replace(amount, ",", ".", 1)
+	}
+
+	reais, cents, _ := strings.Cut(amount, ".")
+
+	if reais == "" || len(cents) > 2 || OnlyNumbers(reais) != reais || OnlyNumbers(cents) != cents {
+		return 0, ErrInvalidMoney
+	}
+
+	cents = (cents + "00")[:2]
+
+	value, err := strconv.ParseInt(reais+cents, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidMoney
+	}
+
+	return Money(sign * value), nil
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestValues_MoneyString(t *testing.T) {
+	tests := []struct {
+		input Money
+		want  string
+	}{
+		{116037, "R$ 1.160,37"},
+		{29, "R$ 0,29"},
+		{0, "R$ 0,00"},
+		{100, "R$ 1,00"},
+		{99999999999, "R$ 999.999.999,99"},
+		{-123456, "-R$ 1.234,56"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.input.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(tt.input), got, tt.want)
+		}
+	}
+}
+
+func TestValues_MoneyDecimal(t *testing.T) {
+	tests := []struct {
+		input Money
+		want  string
+	}{
+		{116037, "1160.37"},
+		{5, "0.05"},
+		{-29, "-0.29"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.input.Decimal(); got != tt.want {
+			t.Errorf("Money(%d).Decimal() = %q, want %q", int64(tt.input), got, tt.want)
+		}
+	}
+}
+
+func TestValues_ParseMoney(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+		err   error
+	}{
+		{"1160.37", 116037, nil},
+		{"R$ 1.160,37", 116037, nil},
+		{"0,29", 29, nil},
+		{"12.5", 1250, nil},
+		{"845", 84500, nil},
+		{"-3.20", -320, nil},
+		{"1.160.37", 0, ErrInvalidMoney},
+		{"0.295", 0, ErrInvalidMoney},
+		{"abc", 0, ErrInvalidMoney},
+		{"", 0, ErrInvalidMoney},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.input)
+
+		if got != tt.want || err != tt.err {
+			t.Errorf("ParseMoney(%q) = %d, %v, want %d, %v", tt.input, int64(got), err, int64(tt.want), tt.err)
+		}
+	}
+}
@@ -21,6 +21,29 @@ const (
 	Bank               BoletoType = "BANK"
 )
 
+// Segments maps the arrecadação segment digit (second position of the code) to its BoletoType
+var Segments = map[string]BoletoType{
+	"1": CityHalls,
+	"2": Sanitation,
+	"3": ElectricityAndGas,
+	"4": Telecommunications,
+	"5": GovernmentAgencies,
+	"6": PaymentBooklets,
+	"7": TrafficFines,
+	"9": PaymentBooklets,
+}
+
+// SegmentCodes maps a BoletoType to the arrecadação segment digit used when generating codes
+var SegmentCodes = map[BoletoType]string{
+	CityHalls:          "1",
+	Sanitation:         "2",
+	ElectricityAndGas:  "3",
+	Telecommunications: "4",
+	GovernmentAgencies: "5",
+	PaymentBooklets:    "6",
+	TrafficFines:       "7",
+}
+
 // Substr returns the portion of string specified by the start and length parameters.
 func Substr(input string, start int, length int) string {
 	asRunes := []rune(input)
@@ -90,3 +113,63 @@ func CalculateVerificationDigit(block string) string {
 
 	return strconv.Itoa(10 - remainder)
 }
+
+// CalculateMod11VerificationDigit calculates the module 11 check digit used by
+// arrecadação codes, where remainders 0 and 1 map to 0.
+func CalculateMod11VerificationDigit(block string) string {
+	sum := 0
+	weight := 2
+
+	// Iterate through digits from right to left, weights cycle from 2 to 9
+	for i := len(block) - 1; i >= 0; i-- {
+		digit, _ := strconv.Atoi(string(block[i]))
+		sum += digit * weight
+
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+
+	remainder := sum % 11
+	if remainder == 0 || remainder == 1 {
+		return "0"
+	}
+
+	return strconv.Itoa(11 - remainder)
+}
+
+// CalculateGeneralVerificationDigit calculates the module 11 general check digit (DAC) of a
+// bank boleto from the 43 barcode digits that surround it, mapping results 0, 10 and 11 to 1.
+func CalculateGeneralVerificationDigit(barcode string) string {
+	sum := 0
+	weight := 2
+
+	// Iterate through digits from right to left, weights cycle from 2 to 9
+	for i := len(barcode) - 1; i >= 0; i-- {
+		digit, _ := strconv.Atoi(string(barcode[i]))
+		sum += digit * weight
+
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+
+	result := 11 - sum%11
+	if result == 0 || result == 10 || result == 11 {
+		return "1"
+	}
+
+	return strconv.Itoa(result)
+}
+
+// CalculateArrecadacaoVerificationDigit calculates an arrecadação check digit with the module
+// selected by the value identifier: module 10 for 6 and 7, module 11 for 8 and 9.
+func CalculateArrecadacaoVerificationDigit(valueIdentifier int, block string) string {
+	if valueIdentifier == 8 || valueIdentifier == 9 {
+		return CalculateMod11VerificationDigit(block)
+	}
+
+	return CalculateVerificationDigit(block)
+}
@@ -0,0 +1,207 @@
+package validator
+
+import (
+	"github.com/fonini/go-boleto-utils/banks"
+	"github.com/fonini/go-boleto-utils/parser"
+	"github.com/fonini/go-boleto-utils/utils"
+	"strconv"
+	"unicode"
+)
+
+// Field identifies the part of a code checked by ValidateDetailed
+type Field string
+
+const (
+	FieldLength            Field = "LENGTH"
+	FieldNumeric           Field = "NUMERIC"
+	FieldValueIdentifier   Field = "VALUE_IDENTIFIER"
+	FieldCheckDigit1       Field = "CHECK_DIGIT_1"
+	FieldCheckDigit2       Field = "CHECK_DIGIT_2"
+	FieldCheckDigit3       Field = "CHECK_DIGIT_3"
+	FieldCheckDigit4       Field = "CHECK_DIGIT_4"
+	FieldGeneralCheckDigit Field = "GENERAL_CHECK_DIGIT"
+	FieldDueDateFactor     Field = "DUE_DATE_FACTOR"
+	FieldBank              Field = "BANK"
+)
+
+var fieldCheckDigits = []Field{FieldCheckDigit1, FieldCheckDigit2, FieldCheckDigit3, FieldCheckDigit4}
+
+// FieldResult is the outcome of a single check. Expected and Actual hold the expected and
+// found digits (or values), and Offset is the character offset of the field in the original
+// input, or -1 when the check does not refer to a position.
+type FieldResult struct {
+	Field    Field
+	Valid    bool
+	Expected string
+	Actual   string
+	Offset   int
+}
+
+// Result holds every check performed on a code by ValidateDetailed
+type Result struct {
+	Input    string
+	CodeType utils.BoletoCodeType
+	Fields   []FieldResult
+}
+
+// Valid reports whether every check passed. An unknown bank does not make the code
+// invalid, since the bank registry may not list every issuer.
+func (r *Result) Valid() bool {
+	return r.Err() == nil
+}
+
+// Failures returns the checks that did not pass, including an unknown bank
+func (r *Result) Failures() []FieldResult {
+	var failures []FieldResult
+
+	for _, field := range r.Fields {
+		if !field.Valid {
+			failures = append(failures, field)
+		}
+	}
+
+	return failures
+}
+
+// Err returns the error matching the first failed check, or nil if the code is valid
+func (r *Result) Err() error {
+	for _, field := range r.Fields {
+		if field.Valid {
+			continue
+		}
+
+		switch field.Field {
+		case FieldBank:
+			continue
+		case FieldCheckDigit1, FieldCheckDigit2, FieldCheckDigit3, FieldCheckDigit4:
+			return ErrFieldCheckDigit
+		case FieldGeneralCheckDigit:
+			return ErrGeneralCheckDigit
+		case FieldDueDateFactor:
+			return ErrDueDateFactor
+		default:
+			return ErrInvalidCode
+		}
+	}
+
+	return nil
+}
+
+// ValidateDetailed validates a digitable line or barcode and reports the outcome of every check.
+// It is stricter than Validate and Check: blanks, '.' and '-' separate the digits, and any
+// other character fails the NUMERIC check at its offset.
+func ValidateDetailed(code string) *Result {
+	result := &Result{Input: code, CodeType: parser.Unknown}
+
+	var digits string
+	var offsets []int
+
+	numeric := FieldResult{Field: FieldNumeric, Valid: true, Offset: -1}
+
+	for i, r := range []rune(code) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits += string(r)
+			offsets = append(offsets, i)
+		case unicode.IsSpace(r) || r == '.' || r == '-':
+		case numeric.Valid:
+			numeric = FieldResult{Field: FieldNumeric, Actual: string(r), Offset: i}
+		}
+	}
+
+	arrecadacao := len(digits) > 0 && digits[0] == '8'
+
+	length := FieldResult{Field: FieldLength, Expected: "44 or 47", Actual: strconv.Itoa(len(digits)), Offset: -1}
+	if arrecadacao {
+		length.Expected = "44 or 48"
+	}
+	length.Valid = len(digits) == 44 || (arrecadacao && len(digits) == 48) || (!arrecadacao && len(digits) == 47)
+
+	result.Fields = append(result.Fields, length, numeric)
+
+	if !length.Valid {
+		return result
+	}
+
+	result.CodeType, _ = parser.GetCodeType(digits)
+
+	if arrecadacao {
+		result.Fields = append(result.Fields, arrecadacaoFields(digits, offsets, result.CodeType)...)
+	} else {
+		result.Fields = append(result.Fields, bankFields(digits, offsets, result.CodeType)...)
+	}
+
+	return result
+}
+
+func bankFields(digits string, offsets []int, codeType utils.BoletoCodeType) []FieldResult {
+	var fields []FieldResult
+
+	barcode := digits
+	generalIndex, factorIndex := 4, 5
+
+	if codeType == parser.DigitableLine {
+		for i, block := range [][2]int{{0, 9}, {10, 20}, {21, 31}} {
+			expected := utils.CalculateVerificationDigit(digits[block[0]:block[1]])
+			fields = append(fields, checkDigitField(fieldCheckDigits[i], expected, digits, offsets, block[1]))
+		}
+
+		barcode = digits[0:4] + digits[32:47] + digits[4:9] + digits[10:20] + digits[21:31]
+		generalIndex, factorIndex = 32, 33
+	}
+
+	expected := utils.CalculateGeneralVerificationDigit(barcode[:4] + barcode[5:])
+	fields = append(fields, checkDigitField(FieldGeneralCheckDigit, expected, digits, offsets, generalIndex))
+
+	factor := barcode[5:9]
+	fields = append(fields, FieldResult{
+		Field:  FieldDueDateFactor,
+		Valid:  factor == "0000" || factor >= "1000",
+		Actual: factor,
+		Offset: offsets[factorIndex],
+	})
+
+	_, err := banks.ByCode(barcode[0:3])
+	fields = append(fields, FieldResult{Field: FieldBank, Valid: err == nil, Actual: barcode[0:3], Offset: offsets[0]})
+
+	return fields
+}
+
+func arrecadacaoFields(digits string, offsets []int, codeType utils.BoletoCodeType) []FieldResult {
+	var fields []FieldResult
+
+	valueIdentifier, err := strconv.Atoi(digits[2:3])
+	if err != nil || valueIdentifier < 6 {
+		return append(fields, FieldResult{Field: FieldValueIdentifier, Expected: "6, 7, 8 or 9", Actual: digits[2:3], Offset: offsets[2]})
+	}
+
+	barcode := digits
+
+	if codeType == parser.DigitableLine {
+		barcode = ""
+
+		for i := 0; i < len(digits); i += 12 {
+			block := digits[i : i+11]
+			expected := utils.CalculateArrecadacaoVerificationDigit(valueIdentifier, block)
+			fields = append(fields, checkDigitField(fieldCheckDigits[i/12], expected, digits, offsets, i+11))
+
+			barcode += block
+		}
+	}
+
+	expected := utils.CalculateArrecadacaoVerificationDigit(valueIdentifier, barcode[:3]+barcode[4:])
+
+	return append(fields, checkDigitField(FieldGeneralCheckDigit, expected, digits, offsets, 3))
+}
+
+func checkDigitField(field Field, expected string, digits string, offsets []int, index int) FieldResult {
+	actual := digits[index : index+1]
+
+	return FieldResult{
+		Field:    field,
+		Valid:    expected == actual,
+		Expected: expected,
+		Actual:   actual,
+		Offset:   offsets[index],
+	}
+}
@@ -1,35 +1,31 @@
 package validator
 
 import (
-	"fmt"
-	"github.com/fonini/go-boleto-utils/parser"
+	"errors"
 	"github.com/fonini/go-boleto-utils/utils"
 )
 
-func Validate(code string) bool {
-	boleto, err := parser.Parse(code)
-
-	if err != nil {
-		return false
-	}
-
-	var blocks []string
-
-	blocks = append(blocks, fmt.Sprintf("%s%d%s%d", boleto.IssuerBankCode, boleto.Currency, boleto.IssuerReserved1, boleto.CheckDigit1))
-	blocks = append(blocks, fmt.Sprintf("%s%d", boleto.IssuerReserved2, boleto.CheckDigit2))
-	blocks = append(blocks, fmt.Sprintf("%s%d", boleto.IssuerReserved3, boleto.CheckDigit3))
+var (
+	// ErrInvalidCode is returned when the code has an invalid length, characters or value identifier
+	ErrInvalidCode = errors.New("invalid code")
+	// ErrFieldCheckDigit is returned when a field check digit of the digitable line does not match
+	ErrFieldCheckDigit = errors.New("invalid field check digit")
+	// ErrGeneralCheckDigit is returned when the general check digit (DAC) of the barcode does not match
+	ErrGeneralCheckDigit = errors.New("invalid general check digit")
+	// ErrDueDateFactor is returned when the due date factor is outside the range used by banks
+	ErrDueDateFactor = errors.New("invalid due date factor")
+)
 
-	return validateBlocks(blocks)
+// Validate reports whether code is a valid digitable line or barcode. Characters other than
+// digits are ignored, so "34191.75124/34567..." is read as its digits.
+func Validate(code string) bool {
+	return Check(code) == nil
 }
 
-func validateBlocks(blocks []string) bool {
-	var validCount = 0
-
-	for _, block := range blocks {
-		if utils.Mod10CheckDigit(block) {
-			validCount++
-		}
-	}
-
-	return validCount == len(blocks)
+// Check validates a digitable line or barcode and returns the kind of failure, or nil if it is valid.
+// Arrecadação codes (starting with "8") are validated with the module selected by their value identifier.
+// Like Validate, it ignores characters other than digits. Use ValidateDetailed to get the
+// outcome of every check.
+func Check(code string) error {
+	return ValidateDetailed(utils.OnlyNumbers(code)).Err()
 }
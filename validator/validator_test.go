@@ -1,7 +1,10 @@
 package validator
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 // test that input matches the value we want. If not, report an error on t.
@@ -19,6 +22,12 @@ func TestValues_Validate(t *testing.T) {
 		want  bool
 	}{
 		{"34191.75124 34567.871230 41234.560005 8 92850000026035",
+			false,
+		},
+		{"34191.75124 34567.871230 41234.560005 7 92850000026035",
+			true,
+		},
+		{"34191/75124 34567_871230 41234:560005 7 92850000026035",
 			true,
 		},
 		{"23793.38128 60005.963347 21000.063301 1 74640000116037",
@@ -57,6 +66,36 @@ func TestValues_Validate(t *testing.T) {
 		{"34191990600000005001092664672997197273480000",
 			true,
 		},
+		{"826700000035 645607980002 010002351038 822024116714",
+			true,
+		},
+		{"82670000003645607980000100023510382202411671",
+			true,
+		},
+		{"836800000033 380600863225 535337514090 100168807509",
+			true,
+		},
+		{"85860000000 4 83740385242 0 43070124241 5 85141630306 0",
+			true,
+		},
+		{"85860000000837403852424307012424185141630306",
+			true,
+		},
+		{"856500000026 056505152027 411292024030 335182000000",
+			true,
+		},
+		{"846800000008 550000791008 011193989719 924101544345",
+			true,
+		},
+		{"826700000035 645607980003 010002351038 822024116714",
+			false,
+		},
+		{"85860000000 4 83740385242 0 43070124241 5 85141630306 1",
+			false,
+		},
+		{"82670000003645607980000100023510382202411672",
+			false,
+		},
 		{
 			input: "2325435435",
 			want:  false,
@@ -67,3 +106,132 @@ func TestValues_Validate(t *testing.T) {
 		testValue(t, tt.input, tt.want)
 	}
 }
+
+func TestValues_Check(t *testing.T) {
+	tests := []struct {
+		input string
+		want  error
+	}{
+		{"34191.75124 34567.871230 41234.560005 7 92850000026035",
+			nil,
+		},
+		{"34191.75124 34567.871230 41234.560005 7 92850000026036",
+			ErrGeneralCheckDigit,
+		},
+		{"34191.75124 34567.871230 41234.560005 7 92860000026035",
+			ErrGeneralCheckDigit,
+		},
+		{"34191.75125 34567.871230 41234.560005 7 92850000026035",
+			ErrFieldCheckDigit,
+		},
+		{"34191990600000005001092664672997197273480000",
+			nil,
+		},
+		{"34191990600000006001092664672997197273480000",
+			ErrGeneralCheckDigit,
+		},
+		{"826700000035 645607980002 010002351038 822024116714",
+			nil,
+		},
+		{"826700000035 645607980003 010002351038 822024116714",
+			ErrFieldCheckDigit,
+		},
+		{"826700000043 645607980002 010002351038 822024116714",
+			ErrGeneralCheckDigit,
+		},
+		{"85860000000837403852424307012424185141630307",
+			ErrGeneralCheckDigit,
+		},
+		{"2325435435",
+			ErrInvalidCode,
+		},
+		{"34191/75124 34567_871230 41234:560005 7 92850000026035",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		err := Check(tt.input)
+
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Check(%v) = %v, want %v", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestValues_ValidateDetailed(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []FieldResult
+	}{
+		{"34191.75124 34567.871230 41234.560005 7 92850000026035",
+			nil,
+		},
+		{"34191.75125 34567.871230 41234.560005 7 92850000026035",
+			[]FieldResult{
+				{Field: FieldCheckDigit1, Expected: "4", Actual: "5", Offset: 10},
+			},
+		},
+		{"34191.75124 34567.871230 41234.560005 8 92850000026035",
+			[]FieldResult{
+				{Field: FieldGeneralCheckDigit, Expected: "7", Actual: "8", Offset: 38},
+			},
+		},
+		{"34191.75124 34567.871230 41234.56O005 7 92850000026035",
+			[]FieldResult{
+				{Field: FieldLength, Expected: "44 or 47", Actual: "46", Offset: -1},
+				{Field: FieldNumeric, Actual: "O", Offset: 33},
+			},
+		},
+		{"34191/75124 34567.871230 41234.560005 7 92850000026035",
+			[]FieldResult{
+				{Field: FieldNumeric, Actual: "/", Offset: 5},
+			},
+		},
+		{"99991.75129 34567.871230 41234.560005 7 92850000026035",
+			[]FieldResult{
+				{Field: FieldBank, Actual: "999", Offset: 0},
+			},
+		},
+		{"34191.75124 34567.871230 41234.560005 4 00500000026035",
+			[]FieldResult{
+				{Field: FieldDueDateFactor, Actual: "0050", Offset: 40},
+			},
+		},
+		{"34191990600000005001092664672997197273480000",
+			nil,
+		},
+		{"826700000035 645607980003 010002351038 822024116714",
+			[]FieldResult{
+				{Field: FieldCheckDigit2, Expected: "2", Actual: "3", Offset: 24},
+			},
+		},
+		{"85960000000 4 83740385242 0 43070124241 5 85141630306 0",
+			[]FieldResult{
+				{Field: FieldCheckDigit1, Expected: "2", Actual: "4", Offset: 12},
+				{Field: FieldGeneralCheckDigit, Expected: "4", Actual: "6", Offset: 3},
+			},
+		},
+		{"85560000000 4 83740385242 0 43070124241 5 85141630306 0",
+			[]FieldResult{
+				{Field: FieldValueIdentifier, Expected: "6, 7, 8 or 9", Actual: "5", Offset: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		failures := ValidateDetailed(tt.input).Failures()
+
+		if diff := cmp.Diff(tt.want, failures); diff != "" {
+			t.Errorf("ValidateDetailed(%v) mismatch:\n%s", tt.input, diff)
+		}
+	}
+}
+
+func TestValues_ValidateDetailedUnknownBank(t *testing.T) {
+	result := ValidateDetailed("99991.75129 34567.871230 41234.560005 7 92850000026035")
+
+	if !result.Valid() {
+		t.Errorf("ValidateDetailed() with an unknown bank should be valid, got %v", result.Err())
+	}
+}